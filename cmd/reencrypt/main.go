@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go_code/simplek8s/server"
+	"go_code/simplek8s/wire"
+	"os"
+)
+
+// reencrypt 使用当前主密钥重新加密所有集群的 kubeconfig，用于轮换主密钥或加密历史明文数据。
+// 旧密钥需要保留在密钥配置中直到命令执行完成。逐行处理，无法解密的行记为失败，不影响其他行
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only report clusters that need to be re-encrypted")
+	flag.Parse()
+
+	// 初始化日志
+	server.InitLogger()
+	defer server.Logger.Sync()
+
+	clusterRepo, err := wire.InitializeClusterRepo()
+	if err != nil {
+		server.Logger.Fatal(err.Error())
+	}
+
+	// 使用注入到 DAO 中的密钥配置，与实际加密时的主密钥一致
+	primary := clusterRepo.PrimaryKeyID()
+
+	// 只读取密钥 ID，GetAll 会解密所有行，一行无法解密就会中断整个轮换
+	clusters, err := clusterRepo.ListKeys()
+	if err != nil {
+		server.Logger.Fatal(err.Error())
+	}
+
+	var rotated, skipped, failed int
+	for _, cluster := range clusters {
+		if *dryRun {
+			switch cluster.KeyID {
+			case primary:
+				skipped++
+			case "":
+				fmt.Printf("cluster %d (%s): plaintext, would encrypt with key %q\n", cluster.ID, cluster.Name, primary)
+				rotated++
+			default:
+				fmt.Printf("cluster %d (%s): would re-encrypt from key %q to %q\n", cluster.ID, cluster.Name, cluster.KeyID, primary)
+				rotated++
+			}
+			continue
+		}
+
+		changed, err := clusterRepo.ReencryptConfig(int(cluster.ID))
+		if err != nil {
+			fmt.Printf("cluster %d (%s): %v\n", cluster.ID, cluster.Name, err)
+			failed++
+			continue
+		}
+		if changed {
+			fmt.Printf("cluster %d (%s): re-encrypted\n", cluster.ID, cluster.Name)
+			rotated++
+		} else {
+			skipped++
+		}
+	}
+
+	fmt.Printf("total: %d, re-encrypted: %d, up to date: %d, failed: %d\n", len(clusters), rotated, skipped, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -2,30 +2,158 @@ package dao
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 
 	"go_code/simplek8s/core/application/repository"
 	"go_code/simplek8s/core/entity"
+	"go_code/simplek8s/internal/encryption"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+const clusterColumns = "id, name, description, environment, labels, config, key_id, data_key, probe, created_at, updated_at"
+
+// mysqlDuplicateEntry MySQL 唯一键冲突的错误码
+const mysqlDuplicateEntry = 1062
+
+// clusterDao 读写 clusters 表，config 列使用信封加密保存
 type clusterDao struct {
-	DB *sql.DB
+	DB       *sql.DB
+	Envelope *encryption.Envelope
+}
+
+func NewClusterDao(db *sql.DB, envelope *encryption.Envelope) repository.ClusterRepo {
+	return &clusterDao{DB: db, Envelope: envelope}
+}
+
+// rowScanner 兼容 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (dao *clusterDao) scanCluster(scanner rowScanner) (entity.Cluster, error) {
+	var cluster entity.Cluster
+	var labels, keyID, dataKey, probe sql.NullString
+	err := scanner.Scan(&cluster.ID, &cluster.Name, &cluster.Description, &cluster.Environment, &labels,
+		&cluster.Config, &keyID, &dataKey, &probe, &cluster.CreatedAt, &cluster.UpdatedAt)
+	if err != nil {
+		return cluster, err
+	}
+
+	cluster.KeyID = keyID.String
+	if cluster.Config, err = dao.openConfig(int64(cluster.ID), cluster.Config, keyID.String, dataKey.String); err != nil {
+		return cluster, fmt.Errorf("failed to decrypt config of cluster %d: %v", cluster.ID, err)
+	}
+
+	cluster.Labels = map[string]string{}
+	if labels.Valid && labels.String != "" {
+		if err := json.Unmarshal([]byte(labels.String), &cluster.Labels); err != nil {
+			return cluster, fmt.Errorf("failed to decode labels of cluster %d: %v", cluster.ID, err)
+		}
+	}
+	if probe.Valid && probe.String != "" {
+		cluster.Probe = &entity.ClusterProbe{}
+		if err := json.Unmarshal([]byte(probe.String), cluster.Probe); err != nil {
+			return cluster, fmt.Errorf("failed to decode probe of cluster %d: %v", cluster.ID, err)
+		}
+	}
+	return cluster, nil
 }
 
-func NewClusterDao(db *sql.DB) repository.ClusterRepo {
-	return &clusterDao{DB: db}
+// configAdditionalData 以行 ID 作为附加数据，密文被复制到其他行后无法解密
+func configAdditionalData(id int64) []byte {
+	return []byte("clusters/" + strconv.FormatInt(id, 10) + "/config")
+}
+
+// sealConfig 加密指定行的 kubeconfig，返回 config、key_id、data_key 三列的值
+func (dao *clusterDao) sealConfig(id int64, config string) (string, string, string, error) {
+	sealed, err := dao.Envelope.Encrypt([]byte(config), configAdditionalData(id))
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to encrypt config: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(sealed.Ciphertext), sealed.KeyID,
+		base64.StdEncoding.EncodeToString(sealed.DataKey), nil
+}
+
+// openConfig 解密指定行的 config 列，key_id 为空的历史数据按明文返回
+func (dao *clusterDao) openConfig(id int64, stored, keyID, dataKey string) (string, error) {
+	if keyID == "" {
+		return stored, nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode config: %v", err)
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode data key: %v", err)
+	}
+
+	plaintext, err := dao.Envelope.Decrypt(encryption.Sealed{KeyID: keyID, DataKey: wrappedKey, Ciphertext: ciphertext}, configAdditionalData(id))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// encodeProbe 探测结果为空时写入 NULL
+func encodeProbe(probe *entity.ClusterProbe) (sql.NullString, error) {
+	if probe == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(probe)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode probe: %v", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func encodeLabels(labels map[string]string) (string, error) {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode labels: %v", err)
+	}
+	return string(data), nil
+}
+
+// wrapWriteError 将唯一键冲突转换为 repository.ErrClusterExists
+func wrapWriteError(err error, name string) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return fmt.Errorf("%w: %s", repository.ErrClusterExists, name)
+	}
+	return fmt.Errorf("failed to execute statement: %v", err)
 }
 
 func (dao *clusterDao) Create(cluster entity.Cluster) (int64, error) {
-	stmt, err := dao.DB.Prepare("INSERT INTO clusters(config) VALUES(?)")
+	labels, err := encodeLabels(cluster.Labels)
+	if err != nil {
+		return 0, err
+	}
+	probe, err := encodeProbe(cluster.Probe)
 	if err != nil {
-		return 0, fmt.Errorf("failed to prepare statement: %v", err)
+		return 0, err
 	}
-	defer stmt.Close()
 
-	result, err := stmt.Exec(cluster.Config)
+	// 密文与行 ID 绑定，需要先插入得到 ID 再写入加密后的 kubeconfig，两步在同一个事务中完成
+	tx, err := dao.DB.Begin()
 	if err != nil {
-		return 0, fmt.Errorf("failed to execute statement: %v", err)
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO clusters(name, description, environment, labels, config, probe) VALUES(?, ?, ?, ?, '', ?)",
+		cluster.Name, cluster.Description, cluster.Environment, labels, probe)
+	if err != nil {
+		return 0, wrapWriteError(err, cluster.Name)
 	}
 
 	id, err := result.LastInsertId()
@@ -33,15 +161,37 @@ func (dao *clusterDao) Create(cluster entity.Cluster) (int64, error) {
 		return 0, fmt.Errorf("failed to get last insert id: %v", err)
 	}
 
+	config, keyID, dataKey, err := dao.sealConfig(id, cluster.Config)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("UPDATE clusters SET config = ?, key_id = ?, data_key = ? WHERE id = ?", config, keyID, dataKey, id); err != nil {
+		return 0, fmt.Errorf("failed to execute statement: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
 	return id, nil
 }
 
 func (dao *clusterDao) GetByID(id int) (entity.Cluster, error) {
-	var cluster entity.Cluster
-	err := dao.DB.QueryRow("SELECT id, config FROM clusters WHERE id = ?", id).Scan(&cluster.ID, &cluster.Config)
+	cluster, err := dao.scanCluster(dao.DB.QueryRow("SELECT "+clusterColumns+" FROM clusters WHERE id = ?", id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return cluster, fmt.Errorf("no cluster found with id %d", id)
+			return cluster, fmt.Errorf("%w with id %d", repository.ErrClusterNotFound, id)
+		}
+		return cluster, fmt.Errorf("failed to query row: %v", err)
+	}
+
+	return cluster, nil
+}
+
+func (dao *clusterDao) GetByName(name string) (entity.Cluster, error) {
+	cluster, err := dao.scanCluster(dao.DB.QueryRow("SELECT "+clusterColumns+" FROM clusters WHERE name = ?", name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return cluster, fmt.Errorf("%w with name %s", repository.ErrClusterNotFound, name)
 		}
 		return cluster, fmt.Errorf("failed to query row: %v", err)
 	}
@@ -50,7 +200,7 @@ func (dao *clusterDao) GetByID(id int) (entity.Cluster, error) {
 }
 
 func (dao *clusterDao) GetAll() ([]entity.Cluster, error) {
-	rows, err := dao.DB.Query("SELECT id, config FROM clusters")
+	rows, err := dao.DB.Query("SELECT " + clusterColumns + " FROM clusters ORDER BY id")
 	if err != nil {
 		return nil, fmt.Errorf("failed to query rows: %v", err)
 	}
@@ -58,8 +208,7 @@ func (dao *clusterDao) GetAll() ([]entity.Cluster, error) {
 
 	var clusters []entity.Cluster
 	for rows.Next() {
-		var cluster entity.Cluster
-		err := rows.Scan(&cluster.ID, &cluster.Config)
+		cluster, err := dao.scanCluster(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %v", err)
 		}
@@ -72,3 +221,138 @@ func (dao *clusterDao) GetAll() ([]entity.Cluster, error) {
 
 	return clusters, nil
 }
+
+func (dao *clusterDao) ListKeys() ([]repository.ClusterKey, error) {
+	rows, err := dao.DB.Query("SELECT id, name, key_id FROM clusters ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rows: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []repository.ClusterKey
+	for rows.Next() {
+		var key repository.ClusterKey
+		var keyID sql.NullString
+		if err := rows.Scan(&key.ID, &key.Name, &keyID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		key.KeyID = keyID.String
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return keys, nil
+}
+
+func (dao *clusterDao) Update(cluster entity.Cluster) error {
+	labels, err := encodeLabels(cluster.Labels)
+	if err != nil {
+		return err
+	}
+
+	result, err := dao.DB.Exec("UPDATE clusters SET name = ?, description = ?, environment = ?, labels = ? WHERE id = ?",
+		cluster.Name, cluster.Description, cluster.Environment, labels, cluster.ID)
+	if err != nil {
+		return wrapWriteError(err, cluster.Name)
+	}
+
+	return checkAffected(result, int(cluster.ID))
+}
+
+func (dao *clusterDao) UpdateConfig(id int, config string) error {
+	sealedConfig, keyID, dataKey, err := dao.sealConfig(int64(id), config)
+	if err != nil {
+		return err
+	}
+
+	result, err := dao.DB.Exec("UPDATE clusters SET config = ?, key_id = ?, data_key = ? WHERE id = ?", sealedConfig, keyID, dataKey, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %v", err)
+	}
+
+	return checkAffected(result, id)
+}
+
+func (dao *clusterDao) PrimaryKeyID() string {
+	return dao.Envelope.PrimaryKeyID()
+}
+
+func (dao *clusterDao) ReencryptConfig(id int) (bool, error) {
+	tx, err := dao.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var stored string
+	var keyID, dataKey sql.NullString
+	err = tx.QueryRow("SELECT config, key_id, data_key FROM clusters WHERE id = ? FOR UPDATE", id).Scan(&stored, &keyID, &dataKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("%w with id %d", repository.ErrClusterNotFound, id)
+		}
+		return false, fmt.Errorf("failed to query row: %v", err)
+	}
+	if keyID.String == dao.Envelope.PrimaryKeyID() {
+		return false, nil
+	}
+
+	config, err := dao.openConfig(int64(id), stored, keyID.String, dataKey.String)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt config of cluster %d: %v", id, err)
+	}
+	sealedConfig, newKeyID, newDataKey, err := dao.sealConfig(int64(id), config)
+	if err != nil {
+		return false, err
+	}
+
+	// 轮换密钥不算作集群信息的修改，保持 updated_at 不变
+	_, err = tx.Exec("UPDATE clusters SET config = ?, key_id = ?, data_key = ?, updated_at = updated_at WHERE id = ?",
+		sealedConfig, newKeyID, newDataKey, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute statement: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return true, nil
+}
+
+func (dao *clusterDao) UpdateProbe(id int, probe *entity.ClusterProbe) error {
+	value, err := encodeProbe(probe)
+	if err != nil {
+		return err
+	}
+
+	result, err := dao.DB.Exec("UPDATE clusters SET probe = ? WHERE id = ?", value, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %v", err)
+	}
+
+	return checkAffected(result, id)
+}
+
+func (dao *clusterDao) Delete(id int) error {
+	result, err := dao.DB.Exec("DELETE FROM clusters WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %v", err)
+	}
+
+	return checkAffected(result, id)
+}
+
+// checkAffected 没有行被修改时返回 repository.ErrClusterNotFound
+func checkAffected(result sql.Result, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w with id %d", repository.ErrClusterNotFound, id)
+	}
+	return nil
+}
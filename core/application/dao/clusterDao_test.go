@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go_code/simplek8s/internal/encryption"
+)
+
+func testDao(t *testing.T, primary string) *clusterDao {
+	t.Helper()
+	provider, err := encryption.NewLocalKeyProvider(map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 32),
+	}, primary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &clusterDao{Envelope: encryption.NewEnvelope(provider)}
+}
+
+func TestSealOpenConfig(t *testing.T) {
+	dao := testDao(t, "k1")
+	config := "apiVersion: v1\nkind: Config\nusers:\n- user:\n    client-key-data: a2V5\n"
+
+	stored, keyID, dataKey, err := dao.sealConfig(1, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyID != "k1" {
+		t.Fatalf("expected key id k1, got %q", keyID)
+	}
+	if strings.Contains(stored, "client-key-data") {
+		t.Fatalf("config stored in plaintext: %s", stored)
+	}
+
+	got, err := dao.openConfig(1, stored, keyID, dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != config {
+		t.Fatalf("expected %q, got %q", config, got)
+	}
+
+	// 主密钥轮换后仍可以解密旧数据
+	got, err = testDao(t, "k2").openConfig(1, stored, keyID, dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != config {
+		t.Fatalf("expected %q, got %q", config, got)
+	}
+}
+
+func TestOpenConfig(t *testing.T) {
+	dao := testDao(t, "k1")
+	stored, _, dataKey, err := dao.sealConfig(1, "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		id      int64
+		stored  string
+		keyID   string
+		dataKey string
+		want    string
+		wantErr string
+	}{
+		{name: "legacy plaintext", id: 1, stored: "apiVersion: v1", want: "apiVersion: v1"},
+		{name: "legacy empty", id: 1, stored: "", want: ""},
+		{name: "encrypted", id: 1, stored: stored, keyID: "k1", dataKey: dataKey, want: "config"},
+		{name: "wrong key id", id: 1, stored: stored, keyID: "k2", dataKey: dataKey, wantErr: "failed to unwrap data key"},
+		{name: "unknown key id", id: 1, stored: stored, keyID: "k3", dataKey: dataKey, wantErr: `encryption key "k3" is not configured`},
+		{name: "copied to another row", id: 2, stored: stored, keyID: "k1", dataKey: dataKey, wantErr: "failed to unwrap data key"},
+		{name: "invalid config encoding", id: 1, stored: "not base64!", keyID: "k1", dataKey: dataKey, wantErr: "failed to decode config"},
+		{name: "invalid data key encoding", id: 1, stored: stored, keyID: "k1", dataKey: "not base64!", wantErr: "failed to decode data key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dao.openConfig(tt.id, tt.stored, tt.keyID, tt.dataKey)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
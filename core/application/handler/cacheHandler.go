@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"go_code/simplek8s/internal/utils"
+)
+
+// GetInformerCacheStats 返回 informer 缓存是否启用以及各集群的同步状态
+func (h *RESTHandler) GetInformerCacheStats(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, h.ClusterService.GetInformerCacheStats())
+}
+
+// GetClusterCacheStatus 返回指定集群 informer 缓存的同步状态，集群尚未被读取过时 started 为 false
+func (h *RESTHandler) GetClusterCacheStatus(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, h.ClusterService.GetClusterCacheStatus(clusterID))
+}
@@ -17,24 +17,15 @@ func NewClusterHandler(clusterService service.ClusterService) *ClusterHandler {
 	return &ClusterHandler{ClusterService: clusterService}
 }
 
-func (h *ClusterHandler) AddCluster(w http.ResponseWriter, r *http.Request) {
-	var cluster entity.Cluster
-	if err := json.NewDecoder(r.Body).Decode(&cluster); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
-		return
-	}
-
-	err := h.ClusterService.AddCluster(cluster)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	utils.RespondWithJSON(w, http.StatusOK, "Cluster added successfully")
+// GetClientPoolStats 获取集群客户端池统计信息的处理函数
+func (h *ClusterHandler) GetClientPoolStats(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, h.ClusterService.GetClientPoolStats())
 }
 
 type CreateDeploymentRequest struct {
-	ClusterID      int    `json:"cluster_id"`
-	DeploymentYAML string `json:"deploymentYAML"`
+	ClusterID      entity.ClusterRef `json:"cluster_id"`
+	DeploymentYAML string            `json:"deploymentYAML"`
+	WaitRequest
 }
 
 func (h *ClusterHandler) CreateDeployment(w http.ResponseWriter, r *http.Request) {
@@ -44,16 +35,34 @@ func (h *ClusterHandler) CreateDeployment(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err := h.ClusterService.CreateDeployment(req.ClusterID, req.DeploymentYAML)
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	waitOpts, ok := req.waitOptions(w)
+	if !ok {
+		return
+	}
+
+	result, err := h.ClusterService.CreateDeployment(clusterID, req.DeploymentYAML, service.CreateOptions{WaitOptions: waitOpts})
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, clusterID, err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, "Deployment created successfully")
+	respondWithManifestResult(w, result)
 }
 
-type UpdateDeploymentRequest CreateDeploymentRequest
+type UpdateDeploymentRequest struct {
+	ClusterID       entity.ClusterRef `json:"cluster_id"`
+	DeploymentYAML  string            `json:"deploymentYAML"`
+	ServerSide      bool              `json:"serverSide"`
+	FieldManager    string            `json:"fieldManager"`
+	Force           bool              `json:"force"`
+	ResourceVersion string            `json:"resourceVersion"`
+	WaitRequest
+}
 
 func (h *ClusterHandler) UpdateDeployment(w http.ResponseWriter, r *http.Request) {
 	var req UpdateDeploymentRequest
@@ -62,19 +71,41 @@ func (h *ClusterHandler) UpdateDeployment(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err := h.ClusterService.UpdateDeployment(req.ClusterID, req.DeploymentYAML)
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	waitOpts, ok := req.waitOptions(w)
+	if !ok {
+		return
+	}
+
+	rollout, err := h.ClusterService.UpdateDeployment(clusterID, req.DeploymentYAML, service.UpdateOptions{
+		ApplyOptions: service.ApplyOptions{
+			ServerSide:   req.ServerSide,
+			FieldManager: req.FieldManager,
+			Force:        req.Force,
+		},
+		WaitOptions:     waitOpts,
+		ResourceVersion: req.ResourceVersion,
+	})
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, clusterID, err)
 		return
 	}
 
+	if rollout != nil {
+		respondWithRollout(w, rollout)
+		return
+	}
 	utils.RespondWithJSON(w, http.StatusOK, "Deployment updated successfully")
 }
 
 type GetDeploymentRequest struct {
-	ClusterID      int    `json:"cluster_id"`
-	Namespace      string `json:"namespace"`
-	DeploymentName string `json:"deploymentName"`
+	ClusterID      entity.ClusterRef `json:"cluster_id"`
+	Namespace      string            `json:"namespace"`
+	DeploymentName string            `json:"deploymentName"`
 }
 
 func (h *ClusterHandler) GetDeployment(w http.ResponseWriter, r *http.Request) {
@@ -89,9 +120,14 @@ func (h *ClusterHandler) GetDeployment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deployment, err := h.ClusterService.GetDeployment(req.ClusterID, req.Namespace, req.DeploymentName)
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	deployment, err := h.ClusterService.GetDeployment(clusterID, req.Namespace, req.DeploymentName)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, clusterID, err)
 		return
 	}
 
@@ -112,9 +148,14 @@ func (h *ClusterHandler) DeleteDeployment(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err := h.ClusterService.DeleteDeployment(req.ClusterID, req.Namespace, req.DeploymentName)
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	err := h.ClusterService.DeleteDeployment(clusterID, req.Namespace, req.DeploymentName)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, clusterID, err)
 		return
 	}
 
@@ -122,8 +163,9 @@ func (h *ClusterHandler) DeleteDeployment(w http.ResponseWriter, r *http.Request
 }
 
 type CreateStatefulSetRequest struct {
-	ClusterID       int    `json:"cluster_id"`
-	StatefulSetYAML string `json:"statefulSetYAML"`
+	ClusterID       entity.ClusterRef `json:"cluster_id"`
+	StatefulSetYAML string            `json:"statefulSetYAML"`
+	WaitRequest
 }
 
 // CreateStatefulSet 创建 StatefulSet 的处理函数
@@ -139,16 +181,34 @@ func (h *ClusterHandler) CreateStatefulSet(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := h.ClusterService.CreateStatefulSet(req.ClusterID, req.StatefulSetYAML)
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	waitOpts, ok := req.waitOptions(w)
+	if !ok {
+		return
+	}
+
+	result, err := h.ClusterService.CreateStatefulSet(clusterID, req.StatefulSetYAML, service.CreateOptions{WaitOptions: waitOpts})
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, clusterID, err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "StatefulSet created successfully"})
+	respondWithManifestResult(w, result)
 }
 
-type UpdateStatefulSetRequest CreateStatefulSetRequest
+type UpdateStatefulSetRequest struct {
+	ClusterID       entity.ClusterRef `json:"cluster_id"`
+	StatefulSetYAML string            `json:"statefulSetYAML"`
+	ServerSide      bool              `json:"serverSide"`
+	FieldManager    string            `json:"fieldManager"`
+	Force           bool              `json:"force"`
+	ResourceVersion string            `json:"resourceVersion"`
+	WaitRequest
+}
 
 // UpdateStatefulSet 更新 StatefulSet 的处理函数
 func (h *ClusterHandler) UpdateStatefulSet(w http.ResponseWriter, r *http.Request) {
@@ -163,19 +223,41 @@ func (h *ClusterHandler) UpdateStatefulSet(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := h.ClusterService.UpdateStatefulSet(req.ClusterID, req.StatefulSetYAML)
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	waitOpts, ok := req.waitOptions(w)
+	if !ok {
+		return
+	}
+
+	rollout, err := h.ClusterService.UpdateStatefulSet(clusterID, req.StatefulSetYAML, service.UpdateOptions{
+		ApplyOptions: service.ApplyOptions{
+			ServerSide:   req.ServerSide,
+			FieldManager: req.FieldManager,
+			Force:        req.Force,
+		},
+		WaitOptions:     waitOpts,
+		ResourceVersion: req.ResourceVersion,
+	})
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, clusterID, err)
 		return
 	}
 
+	if rollout != nil {
+		respondWithRollout(w, rollout)
+		return
+	}
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "StatefulSet updated successfully"})
 }
 
 type GetStatefulSetRequest struct {
-	ClusterID       int    `json:"cluster_id"`
-	Namespace       string `json:"namespace"`
-	StatefulSetName string `json:"statefulSetName"`
+	ClusterID       entity.ClusterRef `json:"cluster_id"`
+	Namespace       string            `json:"namespace"`
+	StatefulSetName string            `json:"statefulSetName"`
 }
 
 // GetStatefulSet 获取 StatefulSet 的处理函数
@@ -191,9 +273,14 @@ func (h *ClusterHandler) GetStatefulSet(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	statefulSet, err := h.ClusterService.GetStatefulSet(req.ClusterID, req.Namespace, req.StatefulSetName)
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	statefulSet, err := h.ClusterService.GetStatefulSet(clusterID, req.Namespace, req.StatefulSetName)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, clusterID, err)
 		return
 	}
 
@@ -214,9 +301,14 @@ func (h *ClusterHandler) DeleteStatefulSet(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := h.ClusterService.DeleteStatefulSet(req.ClusterID, req.Namespace, req.StatefulSetName)
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	err := h.ClusterService.DeleteStatefulSet(clusterID, req.Namespace, req.StatefulSetName)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, clusterID, err)
 		return
 	}
 
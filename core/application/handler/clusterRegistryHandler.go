@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go_code/simplek8s/core/application/service"
+	"go_code/simplek8s/core/entity"
+	"go_code/simplek8s/internal/utils"
+)
+
+type AddClusterRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Environment string            `json:"environment"`
+	Labels      map[string]string `json:"labels"`
+	Config      string            `json:"config"`
+	// Probe 为 true 时注册前连接 API Server 探测版本、节点数和权限
+	Probe          bool   `json:"probe"`
+	ProbeNamespace string `json:"probeNamespace"`
+}
+
+// AddCluster 注册集群的处理函数，kubeconfig 校验失败时返回 400
+func (h *ClusterHandler) AddCluster(w http.ResponseWriter, r *http.Request) {
+	var req AddClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	created, err := h.ClusterService.AddCluster(entity.Cluster{
+		Name:        req.Name,
+		Description: req.Description,
+		Environment: req.Environment,
+		Labels:      req.Labels,
+		Config:      req.Config,
+	}, service.ProbeOptions{Enabled: req.Probe, Namespace: req.ProbeNamespace})
+	if err != nil {
+		respondWithClusterError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, created)
+}
+
+type ListClustersRequest struct {
+	Environment string            `json:"environment"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// ListClusters 列出集群的处理函数，请求体可以为空
+func (h *ClusterHandler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	var req ListClustersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	clusters, err := h.ClusterService.ListClusters(service.ClusterFilter{
+		Environment: req.Environment,
+		Labels:      req.Labels,
+	})
+	if err != nil {
+		respondWithClusterError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, clusters)
+}
+
+type GetClusterRequest struct {
+	ClusterID entity.ClusterRef `json:"cluster_id"`
+}
+
+// GetCluster 获取集群信息的处理函数
+func (h *ClusterHandler) GetCluster(w http.ResponseWriter, r *http.Request) {
+	var req GetClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	cluster, err := h.ClusterService.GetCluster(req.ClusterID)
+	if err != nil {
+		respondWithClusterError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, cluster)
+}
+
+type UpdateClusterRequest struct {
+	ClusterID   entity.ClusterRef `json:"cluster_id"`
+	Name        *string           `json:"name"`
+	Description *string           `json:"description"`
+	Environment *string           `json:"environment"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// UpdateCluster 重命名集群或修改描述、环境、标签的处理函数，未传的字段保持不变
+func (h *ClusterHandler) UpdateCluster(w http.ResponseWriter, r *http.Request) {
+	var req UpdateClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	cluster, err := h.ClusterService.UpdateCluster(req.ClusterID, service.ClusterUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		Environment: req.Environment,
+		Labels:      req.Labels,
+	})
+	if err != nil {
+		respondWithClusterError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, cluster)
+}
+
+type UpdateClusterConfigRequest struct {
+	ClusterID      entity.ClusterRef `json:"cluster_id"`
+	Config         string            `json:"config"`
+	Probe          bool              `json:"probe"`
+	ProbeNamespace string            `json:"probeNamespace"`
+}
+
+// UpdateClusterConfig 替换集群 kubeconfig 的处理函数
+func (h *ClusterHandler) UpdateClusterConfig(w http.ResponseWriter, r *http.Request) {
+	var req UpdateClusterConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	probe, err := h.ClusterService.UpdateClusterConfig(req.ClusterID, req.Config, service.ProbeOptions{
+		Enabled:   req.Probe,
+		Namespace: req.ProbeNamespace,
+	})
+	if err != nil {
+		respondWithClusterError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Cluster config updated successfully",
+		"probe":   probe,
+	})
+}
+
+type ProbeClusterRequest struct {
+	ClusterID entity.ClusterRef `json:"cluster_id"`
+	Namespace string            `json:"namespace"`
+}
+
+// ProbeCluster 重新探测集群并保存结果的处理函数
+func (h *ClusterHandler) ProbeCluster(w http.ResponseWriter, r *http.Request) {
+	var req ProbeClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	probe, err := h.ClusterService.ProbeCluster(req.ClusterID, req.Namespace)
+	if err != nil {
+		respondWithClusterError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, probe)
+}
+
+type DeleteClusterRequest GetClusterRequest
+
+// DeleteCluster 删除集群的处理函数
+func (h *ClusterHandler) DeleteCluster(w http.ResponseWriter, r *http.Request) {
+	var req DeleteClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	if err := h.ClusterService.DeleteCluster(req.ClusterID); err != nil {
+		respondWithClusterError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Cluster delete successfully"})
+}
+
+// resolveCluster 将请求中的集群引用解析为集群 ID，失败时直接写入错误响应
+func (h *ClusterHandler) resolveCluster(w http.ResponseWriter, ref entity.ClusterRef) (int, bool) {
+	clusterID, err := h.ClusterService.ResolveClusterID(ref)
+	if err != nil {
+		respondWithClusterError(w, err)
+		return 0, false
+	}
+	return clusterID, true
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"go_code/simplek8s/core/application/repository"
+	"go_code/simplek8s/core/application/service"
+	"go_code/simplek8s/internal/utils"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// 错误码，客户端可以根据 code 判断失败原因，API Server 返回的错误使用其 reason 作为 code
+const (
+	ErrorCodeClusterNotFound      = "ClusterNotFound"
+	ErrorCodeClusterExists        = "ClusterExists"
+	ErrorCodeInvalidCluster       = "InvalidCluster"
+	ErrorCodeInvalidManifest      = "InvalidManifest"
+	ErrorCodeInvalidRequest       = "InvalidRequest"
+	ErrorCodeTimeout              = "Timeout"
+	ErrorCodeNoKindMatch          = "NoKindMatch"
+	ErrorCodeFieldManagerConflict = "FieldManagerConflict"
+	ErrorCodeClusterUnauthorized  = "ClusterUnauthorized"
+	ErrorCodeClusterForbidden     = "ClusterForbidden"
+	ErrorCodeClusterUnreachable   = "ClusterUnreachable"
+	ErrorCodeInternal             = "InternalError"
+)
+
+// ErrorBody 失败响应的 data 部分
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message"`
+	// ClusterID 出错的集群，集群尚未解析时为空
+	ClusterID int `json:"cluster_id,omitempty"`
+	// Group、Kind、Name 出错的资源，来自 API Server 返回的 details
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind,omitempty"`
+	Name  string `json:"name,omitempty"`
+	// Causes 导致失败的字段，如校验失败的字段路径
+	Causes []ErrorCause `json:"causes,omitempty"`
+	// Conflicts 服务端 apply 时与其他管理者冲突的字段
+	Conflicts         []service.FieldConflict `json:"conflicts,omitempty"`
+	RetryAfterSeconds int32                   `json:"retryAfterSeconds,omitempty"`
+}
+
+// ErrorCause 单个字段的失败原因
+type ErrorCause struct {
+	Type    string `json:"type,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// respondWithError 将服务层错误转换为对应的 HTTP 状态码和 ErrorBody
+func respondWithError(w http.ResponseWriter, clusterID int, err error) {
+	code, body := errorBody(err)
+	body.ClusterID = clusterID
+	utils.RespondWithErrorJSON(w, code, body)
+}
+
+// respondWithClusterError 转换集群尚未解析时的错误
+func respondWithClusterError(w http.ResponseWriter, err error) {
+	respondWithError(w, 0, err)
+}
+
+func errorBody(err error) (int, ErrorBody) {
+	body := ErrorBody{Message: err.Error()}
+
+	var conflictErr *service.ApplyConflictError
+	var apiStatus apierrors.APIStatus
+	var netErr net.Error
+	switch {
+	case errors.Is(err, repository.ErrClusterNotFound):
+		body.Code = ErrorCodeClusterNotFound
+		return http.StatusNotFound, body
+	case errors.Is(err, repository.ErrClusterExists):
+		body.Code = ErrorCodeClusterExists
+		return http.StatusConflict, body
+	case errors.Is(err, service.ErrInvalidCluster):
+		body.Code = ErrorCodeInvalidCluster
+		return http.StatusBadRequest, body
+	case errors.Is(err, service.ErrInvalidManifest):
+		body.Code = ErrorCodeInvalidManifest
+		return http.StatusUnprocessableEntity, body
+	case errors.Is(err, service.ErrInvalidRequest):
+		body.Code = ErrorCodeInvalidRequest
+		return http.StatusBadRequest, body
+	case errors.Is(err, service.ErrTimeout):
+		body.Code = ErrorCodeTimeout
+		return http.StatusGatewayTimeout, body
+	case meta.IsNoMatchError(err):
+		// 集群中没有该资源类型，通常是 apiVersion 写错或 CRD 未安装
+		body.Code = ErrorCodeNoKindMatch
+		return http.StatusUnprocessableEntity, body
+	case errors.As(err, &conflictErr):
+		body.Code = ErrorCodeFieldManagerConflict
+		body.Reason = string(metav1.StatusReasonConflict)
+		body.Kind = conflictErr.Kind
+		body.Name = conflictErr.Name
+		body.Conflicts = conflictErr.Conflicts
+		return http.StatusConflict, body
+	case errors.As(err, &apiStatus):
+		return statusErrorBody(apiStatus.Status(), body)
+	case errors.As(err, &netErr):
+		body.Code = ErrorCodeClusterUnreachable
+		return http.StatusBadGateway, body
+	default:
+		body.Code = ErrorCodeInternal
+		return http.StatusInternalServerError, body
+	}
+}
+
+// statusErrorBody 转换 API Server 返回的错误，状态码沿用 API Server 的状态码，凭据被拒绝时返回 502
+func statusErrorBody(status metav1.Status, body ErrorBody) (int, ErrorBody) {
+	body.Reason = string(status.Reason)
+	body.Code = body.Reason
+	if body.Code == "" {
+		body.Code = ErrorCodeInternal
+	}
+	if details := status.Details; details != nil {
+		body.Group = details.Group
+		body.Kind = details.Kind
+		body.Name = details.Name
+		body.RetryAfterSeconds = details.RetryAfterSeconds
+		for _, cause := range details.Causes {
+			body.Causes = append(body.Causes, ErrorCause{
+				Type:    string(cause.Type),
+				Field:   cause.Field,
+				Message: cause.Message,
+			})
+		}
+	}
+
+	code := int(status.Code)
+	switch {
+	case status.Reason == metav1.StatusReasonUnauthorized || code == http.StatusUnauthorized:
+		// 集群拒绝的是保存的 kubeconfig 凭据，而不是调用方，不能返回 401
+		body.Code = ErrorCodeClusterUnauthorized
+		return http.StatusBadGateway, body
+	case status.Reason == metav1.StatusReasonForbidden || code == http.StatusForbidden:
+		// 保存的凭据没有 RBAC 权限，同样不是调用方被拒绝，reason 保留为 Forbidden
+		body.Code = ErrorCodeClusterForbidden
+		return http.StatusBadGateway, body
+	case code < http.StatusBadRequest:
+		return http.StatusInternalServerError, body
+	default:
+		return code, body
+	}
+}
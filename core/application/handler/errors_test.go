@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"go_code/simplek8s/core/application/repository"
+	"go_code/simplek8s/core/application/service"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestErrorBody(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantReason string
+	}{
+		{"cluster not found", fmt.Errorf("failed to get cluster: %w", repository.ErrClusterNotFound), http.StatusNotFound, ErrorCodeClusterNotFound, ""},
+		{"cluster exists", repository.ErrClusterExists, http.StatusConflict, ErrorCodeClusterExists, ""},
+		{"invalid cluster", fmt.Errorf("%w: bad kubeconfig", service.ErrInvalidCluster), http.StatusBadRequest, ErrorCodeInvalidCluster, ""},
+		{"invalid manifest", service.ErrInvalidManifest, http.StatusUnprocessableEntity, ErrorCodeInvalidManifest, ""},
+		{"invalid request", service.ErrInvalidRequest, http.StatusBadRequest, ErrorCodeInvalidRequest, ""},
+		{"timeout", service.ErrTimeout, http.StatusGatewayTimeout, ErrorCodeTimeout, ""},
+		{"conflict", &service.ApplyConflictError{Kind: "Deployment", Name: "web"}, http.StatusConflict, ErrorCodeFieldManagerConflict, "Conflict"},
+		{"not found", fmt.Errorf("failed to get deployment: %w", apierrors.NewNotFound(deployments, "web")), http.StatusNotFound, "NotFound", "NotFound"},
+		{"invalid", apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "web", nil), http.StatusUnprocessableEntity, "Invalid", "Invalid"},
+		{"upstream unauthorized", apierrors.NewUnauthorized("token expired"), http.StatusBadGateway, ErrorCodeClusterUnauthorized, "Unauthorized"},
+		{"upstream forbidden", apierrors.NewForbidden(deployments, "web", errors.New("RBAC denied")), http.StatusBadGateway, ErrorCodeClusterForbidden, "Forbidden"},
+		{"unreachable", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, http.StatusBadGateway, ErrorCodeClusterUnreachable, ""},
+		{"other", errors.New("boom"), http.StatusInternalServerError, ErrorCodeInternal, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := errorBody(tt.err)
+			if status != tt.wantStatus || body.Code != tt.wantCode || body.Reason != tt.wantReason {
+				t.Fatalf("expected %d %s %q, got %d %s %q", tt.wantStatus, tt.wantCode, tt.wantReason, status, body.Code, body.Reason)
+			}
+			if body.Message != tt.err.Error() {
+				t.Fatalf("expected message %q, got %q", tt.err.Error(), body.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go_code/simplek8s/core/application/service"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// execPongWait 等待客户端响应 ping 的时间，超时后认为连接已断开
+	execPongWait = 60 * time.Second
+	// execPingPeriod 向客户端发送 ping 的间隔，必须小于 execPongWait
+	execPingPeriod = 30 * time.Second
+	// execWriteWait 单次写入 WebSocket 的超时时间
+	execWriteWait = 10 * time.Second
+)
+
+// exec 消息类型，客户端发送 stdin、resize，服务端发送 stdout、stderr、exit、error
+const (
+	ExecMessageStdin  = "stdin"
+	ExecMessageResize = "resize"
+	ExecMessageStdout = "stdout"
+	ExecMessageStderr = "stderr"
+	ExecMessageExit   = "exit"
+	ExecMessageError  = "error"
+)
+
+// ExecMessage WebSocket 上传输的 JSON 文本消息
+type ExecMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data,omitempty"`
+	// Cols、Rows 终端窗口大小，只用于 resize
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	// Code 命令的退出码，只用于 exit
+	Code *int `json:"code,omitempty"`
+}
+
+var execUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     checkOrigin,
+}
+
+var (
+	allowedOriginsOnce sync.Once
+	allowedOrigins     map[string]bool
+)
+
+// checkOrigin 默认只允许同源的 WebSocket 连接，SIMPLEK8S_WS_ALLOWED_ORIGINS 可以配置逗号分隔的其他来源，* 表示不限制
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err == nil && strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+
+	allowedOriginsOnce.Do(func() {
+		allowedOrigins = map[string]bool{}
+		for _, allowed := range strings.Split(os.Getenv("SIMPLEK8S_WS_ALLOWED_ORIGINS"), ",") {
+			if allowed = strings.TrimSpace(allowed); allowed != "" {
+				allowedOrigins[allowed] = true
+			}
+		}
+	})
+	return allowedOrigins["*"] || allowedOrigins[origin]
+}
+
+// ExecPod 升级为 WebSocket，在容器中执行命令并双向转发 stdin、stdout、stderr 和终端窗口大小。
+// 查询参数：container、command（可重复，默认 /bin/sh）、tty（默认 true）、stdin（默认 true）
+func (h *RESTHandler) ExecPod(w http.ResponseWriter, r *http.Request) {
+	tty, ok := queryBoolDefault(w, r, "tty", true)
+	if !ok {
+		return
+	}
+	stdin, ok := queryBoolDefault(w, r, "stdin", true)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	session, err := h.ClusterService.PrepareExec(r.Context(), clusterID, vars["namespace"], vars["pod"], service.ExecOptions{
+		Container: r.URL.Query().Get("container"),
+		Command:   r.URL.Query()["command"],
+		TTY:       tty,
+		Stdin:     stdin,
+	})
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	// 升级失败时 Upgrade 已经写入了错误响应
+	conn, err := execUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	ws := &execConn{conn: conn}
+	streams := service.ExecStreams{
+		Stdout: ws.writer(ExecMessageStdout),
+		Stderr: ws.writer(ExecMessageStderr),
+	}
+	var stdinWriter *io.PipeWriter
+	if stdin {
+		var stdinReader *io.PipeReader
+		stdinReader, stdinWriter = io.Pipe()
+		// 命令退出后关闭管道，让 readLoop 中阻塞的写入返回
+		defer stdinReader.Close()
+		streams.Stdin = stdinReader
+	}
+	resize := make(chan service.TerminalSize, 4)
+	streams.Resize = resize
+	go ws.readLoop(cancel, stdinWriter, resize)
+	go ws.pingLoop(ctx)
+
+	code, err := session.Stream(ctx, streams)
+	if err != nil {
+		if ctx.Err() == nil {
+			ws.write(ExecMessage{Type: ExecMessageError, Data: err.Error()})
+		}
+		ws.close(websocket.CloseInternalServerErr, "exec failed")
+		return
+	}
+	ws.write(ExecMessage{Type: ExecMessageExit, Code: &code})
+	ws.close(websocket.CloseNormalClosure, "")
+}
+
+// execConn WebSocket 连接，同一时间只能有一个写入者，stdout、stderr、ping 通过 mu 串行写入
+type execConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (c *execConn) write(message ExecMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(execWriteWait))
+	return c.conn.WriteJSON(message)
+}
+
+func (c *execConn) close(code int, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(execWriteWait))
+}
+
+// readLoop 读取客户端消息，stdin 写入管道，resize 写入 channel，连接断开时取消执行。
+// 未开启 stdin 时 stdin 为 nil，客户端发送的输入被忽略
+func (c *execConn) readLoop(cancel context.CancelFunc, stdin *io.PipeWriter, resize chan<- service.TerminalSize) {
+	defer cancel()
+	defer close(resize)
+	if stdin != nil {
+		// 客户端断开时让命令读到 EOF
+		defer stdin.Close()
+	}
+
+	c.conn.SetReadDeadline(time.Now().Add(execPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(execPongWait))
+	})
+	for {
+		var message ExecMessage
+		if err := c.conn.ReadJSON(&message); err != nil {
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(execPongWait))
+
+		switch message.Type {
+		case ExecMessageStdin:
+			if stdin == nil {
+				continue
+			}
+			if _, err := stdin.Write([]byte(message.Data)); err != nil {
+				return
+			}
+		case ExecMessageResize:
+			if message.Cols == 0 || message.Rows == 0 {
+				continue
+			}
+			select {
+			case resize <- service.TerminalSize{Cols: message.Cols, Rows: message.Rows}:
+			default:
+				// 调整窗口的速度跟不上时丢弃中间的大小
+			}
+		}
+	}
+}
+
+func (c *execConn) pingLoop(ctx context.Context) {
+	ticker := time.NewTicker(execPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(execWriteWait))
+			c.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writer 将输出包装为指定类型的消息
+func (c *execConn) writer(messageType string) io.Writer {
+	return &execWriter{conn: c, messageType: messageType}
+}
+
+type execWriter struct {
+	conn        *execConn
+	messageType string
+	// pending 上一次写入末尾不完整的 UTF-8 字符，与下一次写入合并后再发送
+	pending []byte
+}
+
+func (w *execWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	n := len(data)
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				n = len(data) - i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), data[n:]...)
+
+	if n > 0 {
+		if err := w.conn.write(ExecMessage{Type: w.messageType, Data: string(data[:n])}); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	t.Setenv("SIMPLEK8S_WS_ALLOWED_ORIGINS", " https://admin.example.com ,")
+	tests := []struct {
+		name   string
+		host   string
+		origin string
+		want   bool
+	}{
+		// 非浏览器客户端不发送 Origin
+		{name: "no origin", host: "api.example.com", want: true},
+		{name: "same origin", host: "api.example.com", origin: "https://api.example.com", want: true},
+		{name: "same origin case insensitive", host: "api.example.com:8080", origin: "http://API.example.com:8080", want: true},
+		{name: "allowed origin", host: "api.example.com", origin: "https://admin.example.com", want: true},
+		{name: "cross origin", host: "api.example.com", origin: "https://evil.example.com", want: false},
+		{name: "different port", host: "api.example.com:8080", origin: "https://api.example.com", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/clusters/1/namespaces/prod/pods/web/exec", nil)
+			r.Host = tt.host
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := checkOrigin(r); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
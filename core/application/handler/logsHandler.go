@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go_code/simplek8s/core/application/service"
+
+	"github.com/gorilla/mux"
+)
+
+// GetPodLogs 读取 Pod 的日志，未指定 container 时读取全部容器。
+// 默认以分块传输返回带 [pod/container] 前缀的文本，Accept: text/event-stream 或 format=sse 时返回 Server-Sent Events
+func (h *RESTHandler) GetPodLogs(w http.ResponseWriter, r *http.Request) {
+	opts, ok := queryLogOptions(w, r)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	stream, err := h.ClusterService.OpenPodLogs(r.Context(), clusterID, vars["namespace"], vars["pod"], opts)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	streamLogs(w, r, stream)
+}
+
+// GetWorkloadLogs 读取 Deployment 或 StatefulSet 控制的所有 Pod 的日志，参数与 GetPodLogs 相同
+func (h *RESTHandler) GetWorkloadLogs(w http.ResponseWriter, r *http.Request) {
+	opts, ok := queryLogOptions(w, r)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	stream, err := h.ClusterService.OpenWorkloadLogs(r.Context(), clusterID, vars["namespace"], workloadKinds[vars["workload"]], vars["name"], opts)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	streamLogs(w, r, stream)
+}
+
+// streamLogs 将日志逐行写给客户端，直到日志流结束或客户端断开
+func streamLogs(w http.ResponseWriter, r *http.Request, stream *service.LogStream) {
+	defer stream.Close()
+
+	sse := wantsEventStream(r)
+	if sse {
+		beginStream(w, "text/event-stream")
+	} else {
+		beginStream(w, "text/plain; charset=utf-8")
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if sse {
+				if err := writeKeepAlive(w); err != nil {
+					return
+				}
+			}
+		case line, ok := <-stream.Lines():
+			if !ok {
+				if sse {
+					writeEvent(w, "end", struct{}{})
+				}
+				return
+			}
+			if err := writeLogLine(w, sse, line); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeLogLine(w http.ResponseWriter, sse bool, line service.LogLine) error {
+	if sse {
+		event := "log"
+		if line.Err != "" {
+			event = "error"
+		}
+		return writeEvent(w, event, line)
+	}
+
+	var err error
+	if line.Err != "" {
+		_, err = fmt.Fprintf(w, "[%s/%s] error: %s\n", line.Pod, line.Container, line.Err)
+	} else {
+		_, err = fmt.Fprintf(w, "[%s/%s] %s\n", line.Pod, line.Container, line.Line)
+	}
+	if err != nil {
+		return err
+	}
+	flush(w)
+	return nil
+}
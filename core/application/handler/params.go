@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go_code/simplek8s/core/application/service"
+	"go_code/simplek8s/core/entity"
+	"go_code/simplek8s/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// maxManifestSize 请求体中清单的最大字节数
+const maxManifestSize = 4 << 20
+
+// pathCluster 解析路径中的 {cluster}，可以是集群 ID 或名称，失败时直接写入错误响应
+func (h *ClusterHandler) pathCluster(w http.ResponseWriter, r *http.Request) (int, bool) {
+	return h.resolveCluster(w, entity.ParseClusterRef(mux.Vars(r)["cluster"]))
+}
+
+// clusterRef 将已解析的集群 ID 转换为集群引用
+func clusterRef(clusterID int) entity.ClusterRef {
+	return entity.ClusterRef{ID: clusterID}
+}
+
+// readManifest 读取请求体中的 YAML 或 JSON 清单，为空时返回 400
+func readManifest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestSize))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Failed to read request body: %v", err))
+		return "", false
+	}
+	if len(body) == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Manifest is required in the request body")
+		return "", false
+	}
+	return string(body), true
+}
+
+// queryBool 读取布尔类型的查询参数，未传时为 false，格式错误时返回 400
+func queryBool(w http.ResponseWriter, r *http.Request, name string) (bool, bool) {
+	return queryBoolDefault(w, r, name, false)
+}
+
+// queryBoolDefault 读取布尔类型的查询参数，未传时为 def，格式错误时返回 400
+func queryBoolDefault(w http.ResponseWriter, r *http.Request, name string, def bool) (bool, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, true
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query parameter %s: %q", name, value))
+		return false, false
+	}
+	return parsed, true
+}
+
+// queryApplyOptions 从查询参数 serverSide、fieldManager、force 读取写入方式
+func queryApplyOptions(w http.ResponseWriter, r *http.Request) (service.ApplyOptions, bool) {
+	serverSide, ok := queryBool(w, r, "serverSide")
+	if !ok {
+		return service.ApplyOptions{}, false
+	}
+	force, ok := queryBool(w, r, "force")
+	if !ok {
+		return service.ApplyOptions{}, false
+	}
+	return service.ApplyOptions{
+		ServerSide:   serverSide,
+		FieldManager: r.URL.Query().Get("fieldManager"),
+		Force:        force,
+	}, true
+}
+
+// queryWaitOptions 从查询参数 wait、timeout（如 90s、5m）、events 读取等待发布的参数
+func queryWaitOptions(w http.ResponseWriter, r *http.Request) (service.WaitOptions, bool) {
+	wait, ok := queryBool(w, r, "wait")
+	if !ok {
+		return service.WaitOptions{}, false
+	}
+	events, ok := queryBool(w, r, "events")
+	if !ok {
+		return service.WaitOptions{}, false
+	}
+	opts := service.WaitOptions{Wait: wait, Events: events}
+	if value := r.URL.Query().Get("timeout"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query parameter timeout: %q", value))
+			return service.WaitOptions{}, false
+		}
+		opts.Timeout = timeout
+	}
+	return opts, true
+}
+
+// WaitRequest 旧路由请求体中等待发布的参数，含义与 wait、timeout、events 查询参数一致
+type WaitRequest struct {
+	Wait bool `json:"wait"`
+	// Timeout 等待的超时时间，如 90s、5m
+	Timeout string `json:"timeout"`
+	Events  bool   `json:"events"`
+}
+
+// waitOptions 校验并转换等待参数，格式错误时返回 400
+func (req WaitRequest) waitOptions(w http.ResponseWriter) (service.WaitOptions, bool) {
+	timeout, ok := parseTimeout(w, req.Timeout)
+	if !ok {
+		return service.WaitOptions{}, false
+	}
+	return service.WaitOptions{Wait: req.Wait, Timeout: timeout, Events: req.Events}, true
+}
+
+// parseTimeout 解析请求体中的等待超时时间，为空时返回 0，格式错误或为负数时返回 400
+func parseTimeout(w http.ResponseWriter, value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, true
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid timeout: %q", value))
+		return 0, false
+	}
+	return timeout, true
+}
+
+// queryDryRun 读取 dryRun 查询参数，预览时不会写入集群，因此不能等待发布
+func queryDryRun(w http.ResponseWriter, r *http.Request, waitOpts service.WaitOptions) (bool, bool) {
+	dryRun, ok := queryBool(w, r, "dryRun")
+	if !ok {
+		return false, false
+	}
+	if dryRun && waitOpts.Wait {
+		utils.RespondWithError(w, http.StatusBadRequest, "Query parameters dryRun and wait cannot be used together")
+		return false, false
+	}
+	return dryRun, true
+}
+
+// queryListOptions 从查询参数 labelSelector、fieldSelector、limit、continue 读取列表参数
+func queryListOptions(w http.ResponseWriter, r *http.Request) (service.ListOptions, bool) {
+	query := r.URL.Query()
+	opts := service.ListOptions{
+		LabelSelector: query.Get("labelSelector"),
+		FieldSelector: query.Get("fieldSelector"),
+		Continue:      query.Get("continue"),
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query parameter limit: %q", value))
+			return service.ListOptions{}, false
+		}
+		opts.Limit = limit
+	}
+	return opts, true
+}
+
+// queryPositiveInt 读取正整数查询参数，未传时为 nil，格式错误时返回 400
+func queryPositiveInt(w http.ResponseWriter, r *http.Request, name string) (*int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed <= 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query parameter %s: %q", name, value))
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// queryLogOptions 从查询参数 container、tailLines、sinceSeconds、previous、follow、timestamps 读取日志参数
+func queryLogOptions(w http.ResponseWriter, r *http.Request) (service.LogOptions, bool) {
+	opts := service.LogOptions{Container: r.URL.Query().Get("container")}
+	var ok bool
+	if opts.TailLines, ok = queryPositiveInt(w, r, "tailLines"); !ok {
+		return service.LogOptions{}, false
+	}
+	if opts.SinceSeconds, ok = queryPositiveInt(w, r, "sinceSeconds"); !ok {
+		return service.LogOptions{}, false
+	}
+	if opts.Previous, ok = queryBool(w, r, "previous"); !ok {
+		return service.LogOptions{}, false
+	}
+	if opts.Follow, ok = queryBool(w, r, "follow"); !ok {
+		return service.LogOptions{}, false
+	}
+	if opts.Timestamps, ok = queryBool(w, r, "timestamps"); !ok {
+		return service.LogOptions{}, false
+	}
+	if opts.Previous && opts.Follow {
+		utils.RespondWithError(w, http.StatusBadRequest, "Query parameters previous and follow cannot be used together")
+		return service.LogOptions{}, false
+	}
+	return opts, true
+}
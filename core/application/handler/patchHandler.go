@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"go_code/simplek8s/core/application/service"
+	"go_code/simplek8s/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// patchContentTypes 未指定 type 查询参数时根据 Content-Type 判断补丁类型，与 kubectl 发送的请求一致
+var patchContentTypes = map[string]string{
+	"application/json-patch+json":            "json",
+	"application/merge-patch+json":           "merge",
+	"application/strategic-merge-patch+json": "strategic",
+	"application/apply-patch+yaml":           "apply",
+}
+
+// PatchResource 对任意资源应用补丁，补丁类型由 type 查询参数（json、merge、strategic、apply）或 Content-Type 指定，
+// 支持 fieldManager、force、dryRun 查询参数。请求体为补丁内容，返回补丁后的对象
+func (h *RESTHandler) PatchResource(w http.ResponseWriter, r *http.Request) {
+	opts, ok := queryPatchOptions(w, r)
+	if !ok {
+		return
+	}
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestSize))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Failed to read request body: %v", err))
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	patched, err := h.ClusterService.PatchResource(clusterID, vars["namespace"], vars["resource"], vars["name"], patch, opts)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, patched.Object)
+}
+
+// queryPatchOptions 读取补丁类型以及 fieldManager、force、dryRun 查询参数
+func queryPatchOptions(w http.ResponseWriter, r *http.Request) (service.PatchOptions, bool) {
+	opts := service.PatchOptions{
+		Type:         r.URL.Query().Get("type"),
+		FieldManager: r.URL.Query().Get("fieldManager"),
+	}
+	if opts.Type == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		opts.Type = patchContentTypes[mediaType]
+	}
+	if opts.Type == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Patch type is required, set the type query parameter or a patch Content-Type")
+		return service.PatchOptions{}, false
+	}
+
+	var ok bool
+	if opts.Force, ok = queryBool(w, r, "force"); !ok {
+		return service.PatchOptions{}, false
+	}
+	if opts.DryRun, ok = queryBool(w, r, "dryRun"); !ok {
+		return service.PatchOptions{}, false
+	}
+	return opts, true
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go_code/simplek8s/core/application/service"
+)
+
+func TestQueryPatchOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		want        service.PatchOptions
+		wantStatus  int
+	}{
+		{name: "type query", query: "type=merge", want: service.PatchOptions{Type: "merge"}},
+		{name: "content type", contentType: "application/strategic-merge-patch+json", want: service.PatchOptions{Type: "strategic"}},
+		{name: "content type with parameters", contentType: "application/apply-patch+yaml; charset=utf-8", want: service.PatchOptions{Type: "apply"}},
+		// 查询参数优先于 Content-Type
+		{name: "query overrides content type", query: "type=json", contentType: "application/merge-patch+json", want: service.PatchOptions{Type: "json"}},
+		{name: "all options", query: "type=apply&fieldManager=ci&force=true&dryRun=1",
+			want: service.PatchOptions{Type: "apply", FieldManager: "ci", Force: true, DryRun: true}},
+		{name: "missing type", contentType: "application/json", wantStatus: http.StatusBadRequest},
+		{name: "invalid force", query: "type=apply&force=yes", wantStatus: http.StatusBadRequest},
+		{name: "invalid dryRun", query: "type=merge&dryRun=maybe", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/clusters/1/namespaces/prod/configmaps/app?"+tt.query, nil)
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			got, ok := queryPatchOptions(w, r)
+			if tt.wantStatus != 0 {
+				if ok || w.Code != tt.wantStatus {
+					t.Fatalf("expected status %d, got ok=%v status %d", tt.wantStatus, ok, w.Code)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("unexpected error response: %d %s", w.Code, w.Body.String())
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
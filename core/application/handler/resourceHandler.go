@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go_code/simplek8s/core/application/service"
+	"go_code/simplek8s/core/entity"
+	"go_code/simplek8s/internal/utils"
+)
+
+type ApplyResourceRequest struct {
+	ClusterID    entity.ClusterRef `json:"cluster_id"`
+	ResourceYAML string            `json:"resourceYAML"`
+	ServerSide   bool              `json:"serverSide"`
+	FieldManager string            `json:"fieldManager"`
+	Force        bool              `json:"force"`
+}
+
+// ApplyResource 按依赖顺序创建或更新清单中全部资源的处理函数
+func (h *ClusterHandler) ApplyResource(w http.ResponseWriter, r *http.Request) {
+	var req ApplyResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	if req.ResourceYAML == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Resource YAML is required")
+		return
+	}
+
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	result, err := h.ClusterService.ApplyManifest(clusterID, req.ResourceYAML, service.ApplyOptions{
+		ServerSide:   req.ServerSide,
+		FieldManager: req.FieldManager,
+		Force:        req.Force,
+	})
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	respondWithManifestResult(w, result)
+}
+
+type DeleteResourceRequest struct {
+	ClusterID    entity.ClusterRef `json:"cluster_id"`
+	ResourceYAML string            `json:"resourceYAML"`
+}
+
+// DeleteResource 按安装顺序的逆序删除清单中全部资源的处理函数
+func (h *ClusterHandler) DeleteResource(w http.ResponseWriter, r *http.Request) {
+	var req DeleteResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	if req.ResourceYAML == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Resource YAML is required")
+		return
+	}
+
+	clusterID, ok := h.resolveCluster(w, req.ClusterID)
+	if !ok {
+		return
+	}
+
+	result, err := h.ClusterService.DeleteManifest(clusterID, req.ResourceYAML)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	respondWithManifestResult(w, result)
+}
+
+// respondWithManifestResult 返回逐个对象的结果，存在失败对象时使用 207 状态码，
+// 等待发布时按发布结果使用 rolloutStatusCode 对应的状态码
+func respondWithManifestResult(w http.ResponseWriter, result *service.ManifestResult) {
+	if result.Failed > 0 {
+		utils.RespondWithErrorJSON(w, http.StatusMultiStatus, result)
+		return
+	}
+	if code := rolloutStatusCode(result.RolloutOutcome()); code != http.StatusOK {
+		utils.RespondWithErrorJSON(w, code, result)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// respondWithPreview 返回清单的预览结果，部分资源预览失败时返回 207
+func respondWithPreview(w http.ResponseWriter, preview *service.ManifestPreview) {
+	if preview.Failed > 0 {
+		utils.RespondWithErrorJSON(w, http.StatusMultiStatus, preview)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, preview)
+}
+
+// respondWithRollout 返回等待结束时的发布状态
+func respondWithRollout(w http.ResponseWriter, rollout *service.RolloutStatus) {
+	if code := rolloutStatusCode(rollout.Outcome); code != http.StatusOK {
+		utils.RespondWithErrorJSON(w, code, rollout)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, rollout)
+}
+
+// rolloutStatusCode 发布失败返回 424（对象已写入，但依赖它的发布失败），等待超时返回 504
+func rolloutStatusCode(outcome string) int {
+	switch outcome {
+	case service.RolloutFailed:
+		return http.StatusFailedDependency
+	case service.RolloutTimeout, service.RolloutProgressing:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusOK
+	}
+}
@@ -0,0 +1,468 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go_code/simplek8s/core/application/service"
+	"go_code/simplek8s/internal/utils"
+
+	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// RESTHandler 面向资源的接口，集群、命名空间和资源名称通过路径参数指定，
+// GET 请求的参数通过查询参数传递，创建和更新时请求体为 YAML 或 JSON 清单
+type RESTHandler struct {
+	*ClusterHandler
+}
+
+func NewRESTHandler(clusterHandler *ClusterHandler) *RESTHandler {
+	return &RESTHandler{ClusterHandler: clusterHandler}
+}
+
+// workloadKinds 路径中的资源类型与 Kind 的对应关系
+var workloadKinds = map[string]string{
+	"deployments":  "Deployment",
+	"statefulsets": "StatefulSet",
+	"daemonsets":   "DaemonSet",
+}
+
+// WorkloadPattern 路由中 {workload} 允许的取值
+const WorkloadPattern = "deployments|statefulsets"
+
+// RestartablePattern 支持滚动重启的 {workload} 取值
+const RestartablePattern = "deployments|statefulsets|daemonsets"
+
+// ListClusters 列出集群，支持 environment 和 labelSelector（如 team=a,tier=web）查询参数
+func (h *RESTHandler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	selector, err := labels.ConvertSelectorToLabelsMap(query.Get("labelSelector"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid labelSelector: %v", err))
+		return
+	}
+
+	clusters, err := h.ClusterService.ListClusters(service.ClusterFilter{
+		Environment: query.Get("environment"),
+		Labels:      selector,
+	})
+	if err != nil {
+		respondWithClusterError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, clusters)
+}
+
+// GetCluster 获取集群信息
+func (h *RESTHandler) GetCluster(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	cluster, err := h.ClusterService.GetCluster(clusterRef(clusterID))
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, cluster)
+}
+
+type PatchClusterRequest struct {
+	Name        *string           `json:"name"`
+	Description *string           `json:"description"`
+	Environment *string           `json:"environment"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// UpdateCluster 修改集群的名称、描述、环境或标签，未传的字段保持不变
+func (h *RESTHandler) UpdateCluster(w http.ResponseWriter, r *http.Request) {
+	var req PatchClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	cluster, err := h.ClusterService.UpdateCluster(clusterRef(clusterID), service.ClusterUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		Environment: req.Environment,
+		Labels:      req.Labels,
+	})
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, cluster)
+}
+
+// UpdateClusterConfig 替换集群的 kubeconfig，请求体为 kubeconfig，probe=true 时重新探测集群
+func (h *RESTHandler) UpdateClusterConfig(w http.ResponseWriter, r *http.Request) {
+	config, ok := readManifest(w, r)
+	if !ok {
+		return
+	}
+	probe, ok := queryBool(w, r, "probe")
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.ClusterService.UpdateClusterConfig(clusterRef(clusterID), config, service.ProbeOptions{
+		Enabled:   probe,
+		Namespace: r.URL.Query().Get("probeNamespace"),
+	})
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Cluster config updated successfully",
+		"probe":   result,
+	})
+}
+
+// ProbeCluster 重新探测集群并保存结果，namespace 查询参数指定检查权限的命名空间
+func (h *RESTHandler) ProbeCluster(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	probe, err := h.ClusterService.ProbeCluster(clusterRef(clusterID), r.URL.Query().Get("namespace"))
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, probe)
+}
+
+// DeleteCluster 删除集群
+func (h *RESTHandler) DeleteCluster(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.ClusterService.DeleteCluster(clusterRef(clusterID)); err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Cluster delete successfully"})
+}
+
+// CreateWorkload 在路径指定的命名空间中创建 Deployment 或 StatefulSet，清单中可以附带依赖资源。
+// wait=true 时等待发布完成，timeout 指定超时时间；dryRun=true 时只返回服务端 dry-run 的预览结果
+func (h *RESTHandler) CreateWorkload(w http.ResponseWriter, r *http.Request) {
+	manifest, ok := readManifest(w, r)
+	if !ok {
+		return
+	}
+	waitOpts, ok := queryWaitOptions(w, r)
+	if !ok {
+		return
+	}
+	dryRun, ok := queryDryRun(w, r, waitOpts)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	opts := service.CreateOptions{WaitOptions: waitOpts, Namespace: vars["namespace"]}
+	if dryRun {
+		preview, err := h.ClusterService.PreviewCreate(clusterID, manifest, workloadKinds[vars["workload"]], opts)
+		if err != nil {
+			respondWithError(w, clusterID, err)
+			return
+		}
+		respondWithPreview(w, preview)
+		return
+	}
+
+	var result *service.ManifestResult
+	var err error
+	switch workloadKinds[vars["workload"]] {
+	case "Deployment":
+		result, err = h.ClusterService.CreateDeployment(clusterID, manifest, opts)
+	case "StatefulSet":
+		result, err = h.ClusterService.CreateStatefulSet(clusterID, manifest, opts)
+	}
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	respondWithManifestResult(w, result)
+}
+
+// UpdateWorkload 更新 Deployment 或 StatefulSet，支持 serverSide、fieldManager、force、resourceVersion、wait、timeout 查询参数。
+// dryRun=true 时不写入集群，返回线上对象与服务端 dry-run 结果之间的差异
+func (h *RESTHandler) UpdateWorkload(w http.ResponseWriter, r *http.Request) {
+	manifest, ok := readManifest(w, r)
+	if !ok {
+		return
+	}
+	applyOpts, ok := queryApplyOptions(w, r)
+	if !ok {
+		return
+	}
+	waitOpts, ok := queryWaitOptions(w, r)
+	if !ok {
+		return
+	}
+	dryRun, ok := queryDryRun(w, r, waitOpts)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	opts := service.UpdateOptions{
+		ApplyOptions:    applyOpts,
+		WaitOptions:     waitOpts,
+		ResourceVersion: r.URL.Query().Get("resourceVersion"),
+		Namespace:       vars["namespace"],
+		Name:            vars["name"],
+	}
+	kind := workloadKinds[vars["workload"]]
+	if dryRun {
+		preview, err := h.ClusterService.PreviewUpdate(clusterID, manifest, kind, opts)
+		if err != nil {
+			respondWithError(w, clusterID, err)
+			return
+		}
+		respondWithPreview(w, preview)
+		return
+	}
+
+	var rollout *service.RolloutStatus
+	var err error
+	switch kind {
+	case "Deployment":
+		rollout, err = h.ClusterService.UpdateDeployment(clusterID, manifest, opts)
+	case "StatefulSet":
+		rollout, err = h.ClusterService.UpdateStatefulSet(clusterID, manifest, opts)
+	}
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	if rollout != nil {
+		respondWithRollout(w, rollout)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": kind + " updated successfully"})
+}
+
+// GetRolloutStatus 获取 Deployment 或 StatefulSet 的发布状态，wait=true 时等待发布完成、失败或超时
+func (h *RESTHandler) GetRolloutStatus(w http.ResponseWriter, r *http.Request) {
+	waitOpts, ok := queryWaitOptions(w, r)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	kind := workloadKinds[vars["workload"]]
+	if !waitOpts.Wait {
+		rollout, err := h.ClusterService.GetRolloutStatus(clusterID, vars["namespace"], kind, vars["name"])
+		if err != nil {
+			respondWithError(w, clusterID, err)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, rollout)
+		return
+	}
+
+	rollout, err := h.ClusterService.WaitForRollout(clusterID, vars["namespace"], kind, vars["name"], waitOpts)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	respondWithRollout(w, rollout)
+}
+
+// ListWorkloads 列出 Deployment 或 StatefulSet 的摘要，路径中没有命名空间时列出所有命名空间。
+// 支持 labelSelector、fieldSelector、limit、continue 查询参数
+func (h *RESTHandler) ListWorkloads(w http.ResponseWriter, r *http.Request) {
+	opts, ok := queryListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	var list *service.WorkloadList
+	var err error
+	switch workloadKinds[vars["workload"]] {
+	case "Deployment":
+		list, err = h.ClusterService.ListDeployments(clusterID, vars["namespace"], opts)
+	case "StatefulSet":
+		list, err = h.ClusterService.ListStatefulSets(clusterID, vars["namespace"], opts)
+	}
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, list)
+}
+
+// GetWorkload 获取 Deployment 或 StatefulSet
+func (h *RESTHandler) GetWorkload(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	var workload interface{}
+	var err error
+	switch workloadKinds[vars["workload"]] {
+	case "Deployment":
+		workload, err = h.ClusterService.GetDeployment(clusterID, vars["namespace"], vars["name"])
+	case "StatefulSet":
+		workload, err = h.ClusterService.GetStatefulSet(clusterID, vars["namespace"], vars["name"])
+	}
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, workload)
+}
+
+// ListWorkloadPods 列出 Deployment 或 StatefulSet 当前控制的 Pod 及其状态
+func (h *RESTHandler) ListWorkloadPods(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	pods, err := h.ClusterService.ListWorkloadPods(clusterID, vars["namespace"], workloadKinds[vars["workload"]], vars["name"])
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, pods)
+}
+
+// ListWorkloadEvents 汇总 Deployment 或 StatefulSet 及其 Pod 的事件，支持 type=Warning 或 type=Normal 查询参数
+func (h *RESTHandler) ListWorkloadEvents(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	events, err := h.ClusterService.ListWorkloadEvents(clusterID, vars["namespace"], workloadKinds[vars["workload"]], vars["name"], service.EventOptions{
+		Type: r.URL.Query().Get("type"),
+	})
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, events)
+}
+
+// DeleteWorkload 删除 Deployment 或 StatefulSet
+func (h *RESTHandler) DeleteWorkload(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	kind := workloadKinds[vars["workload"]]
+	var err error
+	switch kind {
+	case "Deployment":
+		err = h.ClusterService.DeleteDeployment(clusterID, vars["namespace"], vars["name"])
+	case "StatefulSet":
+		err = h.ClusterService.DeleteStatefulSet(clusterID, vars["namespace"], vars["name"])
+	}
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": kind + " delete successfully"})
+}
+
+// ApplyManifest 按依赖顺序创建或更新清单中的全部资源，支持 serverSide、fieldManager、force 查询参数
+func (h *RESTHandler) ApplyManifest(w http.ResponseWriter, r *http.Request) {
+	manifest, ok := readManifest(w, r)
+	if !ok {
+		return
+	}
+	opts, ok := queryApplyOptions(w, r)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.ClusterService.ApplyManifest(clusterID, manifest, opts)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	respondWithManifestResult(w, result)
+}
+
+// DeleteManifest 按安装顺序的逆序删除清单中的全部资源
+func (h *RESTHandler) DeleteManifest(w http.ResponseWriter, r *http.Request) {
+	manifest, ok := readManifest(w, r)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.ClusterService.DeleteManifest(clusterID, manifest)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+
+	respondWithManifestResult(w, result)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"go_code/simplek8s/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// RestartRollout 滚动重启 Deployment、StatefulSet 或 DaemonSet
+func (h *RESTHandler) RestartRollout(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.ClusterService.RestartRollout(clusterID, vars["namespace"], workloadKinds[vars["workload"]], vars["name"])
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// PauseRollout 暂停 Deployment 的发布
+func (h *RESTHandler) PauseRollout(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.ClusterService.PauseRollout(clusterID, vars["namespace"], vars["name"])
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// ResumeRollout 恢复 Deployment 的发布
+func (h *RESTHandler) ResumeRollout(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.ClusterService.ResumeRollout(clusterID, vars["namespace"], vars["name"])
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
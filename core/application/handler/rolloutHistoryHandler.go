@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"go_code/simplek8s/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// GetRolloutHistory 列出 Deployment 或 StatefulSet 的历史版本
+func (h *RESTHandler) GetRolloutHistory(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	revisions, err := h.ClusterService.ListRevisions(clusterID, vars["namespace"], workloadKinds[vars["workload"]], vars["name"])
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, revisions)
+}
+
+// DiffRevisions 比较两个版本的 Pod 模板，查询参数 from 必填，to 未传时与当前版本比较
+func (h *RESTHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	from, ok := queryRevision(w, r, "from")
+	if !ok {
+		return
+	}
+	if from == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Query parameter from is required")
+		return
+	}
+	to, ok := queryRevision(w, r, "to")
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	diff, err := h.ClusterService.DiffRevisions(clusterID, vars["namespace"], workloadKinds[vars["workload"]], vars["name"], from, to)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, diff)
+}
+
+type UndoRolloutRequest struct {
+	// Revision 回滚到的版本，为 0 或不传时回滚到上一个版本
+	Revision int64 `json:"revision"`
+}
+
+// UndoRollout 将 Deployment 或 StatefulSet 的 Pod 模板恢复为指定版本
+func (h *RESTHandler) UndoRollout(w http.ResponseWriter, r *http.Request) {
+	var req UndoRolloutRequest
+	// 请求体可以为空，表示回滚到上一个版本
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+	if req.Revision < 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid revision: %d", req.Revision))
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.ClusterService.UndoRollout(clusterID, vars["namespace"], workloadKinds[vars["workload"]], vars["name"], req.Revision)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// queryRevision 读取版本号查询参数，未传时为 0
+func queryRevision(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || revision <= 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid query parameter %s: %q", name, value))
+		return 0, false
+	}
+	return revision, true
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go_code/simplek8s/core/application/service"
+	"go_code/simplek8s/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// GetScale 获取资源当前的副本数
+func (h *RESTHandler) GetScale(w http.ResponseWriter, r *http.Request) {
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.ClusterService.GetScale(clusterID, vars["namespace"], vars["resource"], vars["name"])
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
+
+type ScaleRequest struct {
+	// Replicas 目标副本数，可以是数字或 "+2"、"-1" 形式的相对值
+	Replicas json.RawMessage `json:"replicas"`
+	Wait     bool            `json:"wait"`
+	// Timeout 等待的超时时间，如 90s、5m
+	Timeout string `json:"timeout"`
+}
+
+// Scale 通过 scale 子资源修改副本数
+func (h *RESTHandler) Scale(w http.ResponseWriter, r *http.Request) {
+	var req ScaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	replicas := string(req.Replicas)
+	if unquoted, err := strconv.Unquote(replicas); err == nil {
+		replicas = unquoted
+	}
+	timeout, ok := parseTimeout(w, req.Timeout)
+	if !ok {
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.ClusterService.Scale(clusterID, vars["namespace"], vars["resource"], vars["name"], service.ScaleOptions{
+		Replicas: replicas,
+		Wait:     req.Wait,
+		Timeout:  timeout,
+	})
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	// 副本数已修改但等待超时，返回 504 并附带修改结果
+	if result.TimedOut {
+		utils.RespondWithErrorJSON(w, http.StatusGatewayTimeout, result)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go_code/simplek8s/core/application/service"
+	"go_code/simplek8s/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type SetImageRequest struct {
+	// Images 容器名称（包括 init 容器）到新镜像的映射
+	Images      map[string]string `json:"images"`
+	ChangeCause string            `json:"changeCause"`
+}
+
+// SetImage 修改 Deployment、StatefulSet 或 DaemonSet 中指定容器的镜像，返回原镜像以便回退
+func (h *RESTHandler) SetImage(w http.ResponseWriter, r *http.Request) {
+	var req SetImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.ClusterService.SetImage(clusterID, vars["namespace"], workloadKinds[vars["workload"]], vars["name"], service.SetImageOptions{
+		Images:      req.Images,
+		ChangeCause: req.ChangeCause,
+	})
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
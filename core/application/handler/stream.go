@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// streamKeepAlive SSE 空闲时发送注释行的间隔，避免代理因长时间没有数据而断开连接
+const streamKeepAlive = 15 * time.Second
+
+// wantsEventStream 客户端通过 Accept: text/event-stream 或 format=sse 请求 Server-Sent Events
+func wantsEventStream(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "sse" || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// beginStream 写入流式响应的响应头并 Flush，之后的写入绕过 JSONResponseMiddleware 直接发送给客户端
+func beginStream(w http.ResponseWriter, contentType string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	// 禁止 nginx 缓冲响应
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flush(w)
+}
+
+func flush(w http.ResponseWriter) {
+	http.NewResponseController(w).Flush()
+}
+
+// writeEvent 写入一个 SSE 事件，data 编码为单行 JSON
+func writeEvent(w http.ResponseWriter, event string, data interface{}) error {
+	return writeEventWithID(w, "", event, data)
+}
+
+// writeEventWithID 写入带 id 的 SSE 事件，EventSource 重连时会在 Last-Event-ID 请求头中带上最后收到的 id
+func writeEventWithID(w http.ResponseWriter, id, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	flush(w)
+	return nil
+}
+
+// writeKeepAlive 写入 SSE 注释行，客户端会忽略
+func writeKeepAlive(w http.ResponseWriter) error {
+	if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	flush(w)
+	return nil
+}
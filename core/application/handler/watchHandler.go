@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"go_code/simplek8s/core/application/service"
+
+	"github.com/gorilla/mux"
+)
+
+// WatchResource 以 Server-Sent Events 推送资源的变化，事件名为 ADDED、MODIFIED、DELETED、BOOKMARK 或 ERROR，
+// 事件 id 为资源版本。支持 labelSelector、fieldSelector、resourceVersion 查询参数，
+// 未传 resourceVersion 时使用 Last-Event-ID 请求头，两者都没有时先推送当前的全部对象
+func (h *RESTHandler) WatchResource(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := service.WatchOptions{
+		LabelSelector:   query.Get("labelSelector"),
+		FieldSelector:   query.Get("fieldSelector"),
+		ResourceVersion: query.Get("resourceVersion"),
+	}
+	if opts.ResourceVersion == "" {
+		opts.ResourceVersion = r.Header.Get("Last-Event-ID")
+	}
+
+	clusterID, ok := h.pathCluster(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	watch, err := h.ClusterService.WatchResource(r.Context(), clusterID, vars["namespace"], vars["resource"], opts)
+	if err != nil {
+		respondWithError(w, clusterID, err)
+		return
+	}
+	defer watch.Stop()
+
+	beginStream(w, "text/event-stream")
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if err := writeKeepAlive(w); err != nil {
+				return
+			}
+		case event, ok := <-watch.Events():
+			if !ok {
+				return
+			}
+			if err := writeEventWithID(w, event.ResourceVersion, event.Type, event); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -1,9 +1,38 @@
 package repository
 
-import "go_code/simplek8s/core/entity"
+import (
+	"errors"
+
+	"go_code/simplek8s/core/entity"
+)
+
+var (
+	ErrClusterNotFound = errors.New("no cluster found")
+	ErrClusterExists   = errors.New("cluster already exists")
+)
+
+// ClusterKey 集群 kubeconfig 的加密信息，读取时不解密
+type ClusterKey struct {
+	ID   uint
+	Name string
+	// KeyID 为空表示明文
+	KeyID string
+}
 
 type ClusterRepo interface {
 	Create(cluster entity.Cluster) (int64, error)
 	GetByID(id int) (entity.Cluster, error)
+	GetByName(name string) (entity.Cluster, error)
 	GetAll() ([]entity.Cluster, error)
+	// Update 更新集群的名称、描述、环境和标签，不修改 kubeconfig
+	Update(cluster entity.Cluster) error
+	UpdateConfig(id int, config string) error
+	UpdateProbe(id int, probe *entity.ClusterProbe) error
+	// ListKeys 列出所有集群加密 kubeconfig 所用的主密钥 ID，不解密数据，无法解密的行不会影响其他行
+	ListKeys() ([]ClusterKey, error)
+	// PrimaryKeyID 返回加密 kubeconfig 使用的当前主密钥 ID
+	PrimaryKeyID() string
+	// ReencryptConfig 使用当前主密钥重新加密 kubeconfig，已是主密钥加密时返回 false
+	ReencryptConfig(id int) (bool, error)
+	Delete(id int) error
 }
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go_code/simplek8s/core/application/repository"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+const (
+	// clientRevalidateInterval 缓存的客户端超过该时间后会重新读取存储的 kubeconfig 并比对是否变化
+	clientRevalidateInterval = 30 * time.Second
+
+	// 同一集群的所有请求共享一个客户端，默认的 5 QPS 限流过低
+	clientQPS   = 50
+	clientBurst = 100
+)
+
+// ClusterClients 单个集群的客户端集合，同一集群的所有请求共享底层连接池
+type ClusterClients struct {
+	Config *rest.Config
+	// HTTPClient 各客户端共享的 HTTP 客户端，需要不同配置的临时客户端时复用其连接池
+	HTTPClient *http.Client
+	Clientset  kubernetes.Interface
+	Dynamic    dynamic.Interface
+	Discovery  discovery.CachedDiscoveryInterface
+	Mapper     meta.ResettableRESTMapper
+
+	clusterID  int
+	configHash string
+	createdAt  time.Time
+	checkedAt  time.Time
+	lastUsed   time.Time
+	hits       int64
+}
+
+// ClientPoolStats 客户端池的统计信息
+type ClientPoolStats struct {
+	Size          int                  `json:"size"`
+	Hits          int64                `json:"hits"`
+	Misses        int64                `json:"misses"`
+	Rebuilds      int64                `json:"rebuilds"`
+	Invalidations int64                `json:"invalidations"`
+	Clusters      []ClusterClientStats `json:"clusters"`
+}
+
+// ClusterClientStats 单个集群客户端的统计信息
+type ClusterClientStats struct {
+	ClusterID int       `json:"cluster_id"`
+	CreatedAt time.Time `json:"createdAt"`
+	CheckedAt time.Time `json:"checkedAt"`
+	LastUsed  time.Time `json:"lastUsed"`
+	Hits      int64     `json:"hits"`
+}
+
+// ClientManager 按集群 ID 懒加载并缓存 REST 配置、clientset、动态客户端和 RESTMapper
+type ClientManager struct {
+	repo repository.ClusterRepo
+
+	mu      sync.Mutex
+	clients map[int]*ClusterClients
+
+	hits          int64
+	misses        int64
+	rebuilds      int64
+	invalidations int64
+}
+
+func NewClientManager(clusterRepo repository.ClusterRepo) *ClientManager {
+	return &ClientManager{
+		repo:    clusterRepo,
+		clients: make(map[int]*ClusterClients),
+	}
+}
+
+// Get 返回指定集群的客户端，缓存过期时会重新读取 kubeconfig，内容变化才重建客户端
+func (m *ClientManager) Get(clusterID int) (*ClusterClients, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	cached, ok := m.clients[clusterID]
+	if ok && now.Sub(cached.checkedAt) < clientRevalidateInterval {
+		m.hits++
+		cached.hits++
+		cached.lastUsed = now
+		m.mu.Unlock()
+		return cached, nil
+	}
+	m.mu.Unlock()
+
+	// 读取数据库和创建客户端都不持有锁，避免慢集群阻塞其他集群的请求
+	cluster, err := m.repo.GetByID(clusterID)
+	if err != nil {
+		if errors.Is(err, repository.ErrClusterNotFound) {
+			m.Invalidate(clusterID)
+		}
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	hash := configHash(cluster.Config)
+
+	if current, ok := m.revalidate(clusterID, hash, now); ok {
+		return current, nil
+	}
+
+	clients, err := newClusterClients(cluster.Config)
+	if err != nil {
+		return nil, err
+	}
+	clients.clusterID = clusterID
+	clients.configHash = hash
+	clients.createdAt = now
+	clients.checkedAt = now
+	clients.lastUsed = now
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 构建期间其他请求可能已经放入相同配置的客户端，使用已有的客户端
+	if current, ok := m.clients[clusterID]; ok && current.configHash == hash {
+		m.hits++
+		current.hits++
+		current.lastUsed = now
+		clients.closeIdleConnections()
+		return current, nil
+	}
+	if previous, exists := m.clients[clusterID]; exists {
+		previous.closeIdleConnections()
+		m.rebuilds++
+	} else {
+		m.misses++
+	}
+	m.clients[clusterID] = clients
+	return clients, nil
+}
+
+// revalidate 缓存的客户端与 kubeconfig 一致时刷新检查时间并返回
+func (m *ClientManager) revalidate(clusterID int, hash string, now time.Time) (*ClusterClients, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.clients[clusterID]
+	if !ok || current.configHash != hash {
+		return nil, false
+	}
+	m.hits++
+	current.hits++
+	current.checkedAt = now
+	current.lastUsed = now
+	return current, true
+}
+
+// Invalidate 丢弃指定集群的缓存客户端，集群配置被修改或删除后调用
+func (m *ClientManager) Invalidate(clusterID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if clients, ok := m.clients[clusterID]; ok {
+		clients.closeIdleConnections()
+		delete(m.clients, clusterID)
+		m.invalidations++
+	}
+}
+
+// Stats 返回客户端池的统计信息
+func (m *ClientManager) Stats() ClientPoolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := ClientPoolStats{
+		Size:          len(m.clients),
+		Hits:          m.hits,
+		Misses:        m.misses,
+		Rebuilds:      m.rebuilds,
+		Invalidations: m.invalidations,
+		Clusters:      make([]ClusterClientStats, 0, len(m.clients)),
+	}
+	for _, clients := range m.clients {
+		stats.Clusters = append(stats.Clusters, ClusterClientStats{
+			ClusterID: clients.clusterID,
+			CreatedAt: clients.createdAt,
+			CheckedAt: clients.checkedAt,
+			LastUsed:  clients.lastUsed,
+			Hits:      clients.hits,
+		})
+	}
+	sort.Slice(stats.Clusters, func(i, j int) bool {
+		return stats.Clusters[i].ClusterID < stats.Clusters[j].ClusterID
+	})
+	return stats
+}
+
+// closeIdleConnections 关闭被替换或淘汰的客户端的空闲连接，正在进行的请求不受影响
+func (c *ClusterClients) closeIdleConnections() {
+	if c.HTTPClient != nil {
+		c.HTTPClient.CloseIdleConnections()
+	}
+}
+
+// newClusterClients 从 kubeconfig 创建共享同一个 HTTP 客户端的各类客户端
+func newClusterClients(kubeconfig string) (*ClusterClients, error) {
+	// 与注册时使用相同的校验，历史数据中的 exec 等凭据也不会被执行
+	parsed, err := parseKubeconfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rest config: %w", err)
+	}
+	config := parsed.restConfig
+	config.QPS = clientQPS
+	config.Burst = clientBurst
+
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %v", err)
+	}
+
+	clientset, err := kubernetes.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+
+	cachedDiscovery := memory.NewMemCacheClient(clientset.Discovery())
+
+	return &ClusterClients{
+		Config:     config,
+		HTTPClient: httpClient,
+		Clientset:  clientset,
+		Dynamic:    dynamicClient,
+		Discovery:  cachedDiscovery,
+		Mapper:     restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscovery),
+	}, nil
+}
+
+func configHash(config string) string {
+	sum := sha256.Sum256([]byte(config))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clusterReader 只读查询使用的数据源。liveReader 直接请求 API Server，
+// cachedReader 在对应资源的 informer 同步完成后从内存读取，返回的对象不能修改
+type clusterReader interface {
+	getDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error)
+	getStatefulSet(ctx context.Context, namespace, name string) (*appsv1.StatefulSet, error)
+	listDeployments(ctx context.Context, namespace string, opts ListOptions) (*appsv1.DeploymentList, error)
+	listStatefulSets(ctx context.Context, namespace string, opts ListOptions) (*appsv1.StatefulSetList, error)
+	listReplicaSets(ctx context.Context, namespace string, selector labels.Selector) ([]appsv1.ReplicaSet, error)
+	listControllerRevisions(ctx context.Context, namespace string, selector labels.Selector) ([]appsv1.ControllerRevision, error)
+	listPods(ctx context.Context, namespace string, selector labels.Selector) ([]corev1.Pod, error)
+	// listEvents eventType 为空时返回所有类型的事件
+	listEvents(ctx context.Context, namespace, eventType string) ([]corev1.Event, error)
+}
+
+// reader 返回集群的只读数据源，写操作和发布状态等需要最新数据的读取直接使用 clientset
+func (s *ClusterService) reader(clients *ClusterClients) clusterReader {
+	return s.Informers.reader(clients)
+}
+
+type liveReader struct {
+	clientset kubernetes.Interface
+}
+
+func (r liveReader) getDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	return r.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (r liveReader) getStatefulSet(ctx context.Context, namespace, name string) (*appsv1.StatefulSet, error) {
+	return r.clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (r liveReader) listDeployments(ctx context.Context, namespace string, opts ListOptions) (*appsv1.DeploymentList, error) {
+	return r.clientset.AppsV1().Deployments(namespace).List(ctx, opts.toListOptions())
+}
+
+func (r liveReader) listStatefulSets(ctx context.Context, namespace string, opts ListOptions) (*appsv1.StatefulSetList, error) {
+	return r.clientset.AppsV1().StatefulSets(namespace).List(ctx, opts.toListOptions())
+}
+
+func (r liveReader) listReplicaSets(ctx context.Context, namespace string, selector labels.Selector) ([]appsv1.ReplicaSet, error) {
+	list, err := r.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (r liveReader) listControllerRevisions(ctx context.Context, namespace string, selector labels.Selector) ([]appsv1.ControllerRevision, error) {
+	list, err := r.clientset.AppsV1().ControllerRevisions(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (r liveReader) listPods(ctx context.Context, namespace string, selector labels.Selector) ([]corev1.Pod, error) {
+	list, err := r.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (r liveReader) listEvents(ctx context.Context, namespace, eventType string) ([]corev1.Event, error) {
+	listOptions := metav1.ListOptions{}
+	if eventType != "" {
+		listOptions.FieldSelector = fields.OneTermEqualSelector("type", eventType).String()
+	}
+	list, err := r.clientset.CoreV1().Events(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// cachedReader 从 informer 读取，资源尚未同步或没有 list、watch 权限时回退到 API Server。ControllerRevision 没有缓存
+type cachedReader struct {
+	live      liveReader
+	informers *clusterInformers
+}
+
+func (r *cachedReader) getDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	if !r.informers.synced("deployments", r.informers.deployments) {
+		return r.live.getDeployment(ctx, namespace, name)
+	}
+	deployment, err := r.informers.deployments.Lister().Deployments(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return deployment.DeepCopy(), nil
+}
+
+func (r *cachedReader) getStatefulSet(ctx context.Context, namespace, name string) (*appsv1.StatefulSet, error) {
+	if !r.informers.synced("statefulsets", r.informers.statefulSets) {
+		return r.live.getStatefulSet(ctx, namespace, name)
+	}
+	statefulSet, err := r.informers.statefulSets.Lister().StatefulSets(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return statefulSet.DeepCopy(), nil
+}
+
+// listDeployments 缓存中没有分页令牌，请求下一页、使用字段选择器或结果超过一页时回退到 API Server
+func (r *cachedReader) listDeployments(ctx context.Context, namespace string, opts ListOptions) (*appsv1.DeploymentList, error) {
+	if !r.informers.synced("deployments", r.informers.deployments) || !cacheListable(opts) {
+		return r.live.listDeployments(ctx, namespace, opts)
+	}
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid labelSelector: %v", ErrInvalidRequest, err)
+	}
+
+	var deployments []*appsv1.Deployment
+	if namespace == "" {
+		deployments, err = r.informers.deployments.Lister().List(selector)
+	} else {
+		deployments, err = r.informers.deployments.Lister().Deployments(namespace).List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(deployments)) > opts.toListOptions().Limit {
+		return r.live.listDeployments(ctx, namespace, opts)
+	}
+
+	list := &appsv1.DeploymentList{Items: make([]appsv1.Deployment, 0, len(deployments))}
+	for _, deployment := range deployments {
+		list.Items = append(list.Items, *deployment)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return objectKeyLess(list.Items[i].ObjectMeta, list.Items[j].ObjectMeta)
+	})
+	return list, nil
+}
+
+func (r *cachedReader) listStatefulSets(ctx context.Context, namespace string, opts ListOptions) (*appsv1.StatefulSetList, error) {
+	if !r.informers.synced("statefulsets", r.informers.statefulSets) || !cacheListable(opts) {
+		return r.live.listStatefulSets(ctx, namespace, opts)
+	}
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid labelSelector: %v", ErrInvalidRequest, err)
+	}
+
+	var statefulSets []*appsv1.StatefulSet
+	if namespace == "" {
+		statefulSets, err = r.informers.statefulSets.Lister().List(selector)
+	} else {
+		statefulSets, err = r.informers.statefulSets.Lister().StatefulSets(namespace).List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(statefulSets)) > opts.toListOptions().Limit {
+		return r.live.listStatefulSets(ctx, namespace, opts)
+	}
+
+	list := &appsv1.StatefulSetList{Items: make([]appsv1.StatefulSet, 0, len(statefulSets))}
+	for _, statefulSet := range statefulSets {
+		list.Items = append(list.Items, *statefulSet)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return objectKeyLess(list.Items[i].ObjectMeta, list.Items[j].ObjectMeta)
+	})
+	return list, nil
+}
+
+func (r *cachedReader) listReplicaSets(ctx context.Context, namespace string, selector labels.Selector) ([]appsv1.ReplicaSet, error) {
+	if !r.informers.synced("replicasets", r.informers.replicaSets) {
+		return r.live.listReplicaSets(ctx, namespace, selector)
+	}
+	replicaSets, err := r.informers.replicaSets.Lister().ReplicaSets(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]appsv1.ReplicaSet, 0, len(replicaSets))
+	for _, replicaSet := range replicaSets {
+		items = append(items, *replicaSet)
+	}
+	return items, nil
+}
+
+func (r *cachedReader) listControllerRevisions(ctx context.Context, namespace string, selector labels.Selector) ([]appsv1.ControllerRevision, error) {
+	return r.live.listControllerRevisions(ctx, namespace, selector)
+}
+
+func (r *cachedReader) listPods(ctx context.Context, namespace string, selector labels.Selector) ([]corev1.Pod, error) {
+	if !r.informers.synced("pods", r.informers.pods) {
+		return r.live.listPods(ctx, namespace, selector)
+	}
+	pods, err := r.informers.pods.Lister().Pods(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		items = append(items, *pod)
+	}
+	return items, nil
+}
+
+func (r *cachedReader) listEvents(ctx context.Context, namespace, eventType string) ([]corev1.Event, error) {
+	if !r.informers.synced("events", r.informers.events) {
+		return r.live.listEvents(ctx, namespace, eventType)
+	}
+	events, err := r.informers.events.Lister().Events(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var items []corev1.Event
+	for _, event := range events {
+		if eventType == "" || event.Type == eventType {
+			items = append(items, *event)
+		}
+	}
+	return items, nil
+}
+
+// cacheListable 缓存只能返回完整的第一页
+func cacheListable(opts ListOptions) bool {
+	return opts.Continue == "" && opts.FieldSelector == ""
+}
+
+// objectKeyLess 与 API Server 列表的顺序一致，先按命名空间再按名称排序
+func objectKeyLess(a, b metav1.ObjectMeta) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
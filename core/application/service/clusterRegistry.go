@@ -0,0 +1,262 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go_code/simplek8s/core/entity"
+)
+
+// ErrInvalidCluster 集群信息校验失败
+var ErrInvalidCluster = errors.New("invalid cluster")
+
+// clusterNamePattern 集群名称需符合 DNS label 规范
+var clusterNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ClusterFilter 列出集群时的过滤条件，为空的条件不参与过滤
+type ClusterFilter struct {
+	Environment string
+	Labels      map[string]string
+}
+
+// ClusterUpdate 集群元数据的部分更新，为 nil 的字段保持不变
+type ClusterUpdate struct {
+	Name        *string
+	Description *string
+	Environment *string
+	Labels      map[string]string
+}
+
+// AddCluster 校验 kubeconfig 后添加新的集群信息，需要时同时保存探测结果
+func (s *ClusterService) AddCluster(cluster entity.Cluster, probe ProbeOptions) (entity.Cluster, error) {
+	if err := validateClusterName(cluster.Name); err != nil {
+		return entity.Cluster{}, err
+	}
+	environment, err := normalizeEnvironment(cluster.Environment)
+	if err != nil {
+		return entity.Cluster{}, err
+	}
+	cluster.Environment = environment
+	if cluster.Config == "" {
+		return entity.Cluster{}, fmt.Errorf("%w: config is required", ErrInvalidCluster)
+	}
+
+	parsed, err := parseKubeconfig(cluster.Config)
+	if err != nil {
+		return entity.Cluster{}, err
+	}
+	cluster.Probe = nil
+	if probe.Enabled {
+		cluster.Probe = probeCluster(parsed, probe.Namespace)
+	}
+
+	id, err := s.ClusterRepo.Create(cluster)
+	if err != nil {
+		return entity.Cluster{}, err
+	}
+
+	created, err := s.ClusterRepo.GetByID(int(id))
+	if err != nil {
+		return entity.Cluster{}, err
+	}
+	return withoutConfig(created), nil
+}
+
+// ResolveClusterID 将集群引用解析为集群 ID，按名称引用时需要查询数据库
+func (s *ClusterService) ResolveClusterID(ref entity.ClusterRef) (int, error) {
+	if ref.Name == "" {
+		if ref.ID <= 0 {
+			return 0, fmt.Errorf("%w: cluster id or name is required", ErrInvalidCluster)
+		}
+		return ref.ID, nil
+	}
+
+	cluster, err := s.ClusterRepo.GetByName(ref.Name)
+	if err != nil {
+		return 0, err
+	}
+	return int(cluster.ID), nil
+}
+
+// ListClusters 列出集群，返回结果不包含 kubeconfig
+func (s *ClusterService) ListClusters(filter ClusterFilter) ([]entity.Cluster, error) {
+	clusters, err := s.ClusterRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]entity.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if filter.Environment != "" && cluster.Environment != filter.Environment {
+			continue
+		}
+		if !matchLabels(cluster.Labels, filter.Labels) {
+			continue
+		}
+		result = append(result, withoutConfig(cluster))
+	}
+	return result, nil
+}
+
+// GetCluster 获取集群信息，返回结果不包含 kubeconfig
+func (s *ClusterService) GetCluster(ref entity.ClusterRef) (entity.Cluster, error) {
+	cluster, err := s.getCluster(ref)
+	if err != nil {
+		return entity.Cluster{}, err
+	}
+	return withoutConfig(cluster), nil
+}
+
+// UpdateCluster 修改集群的名称、描述、环境或标签
+func (s *ClusterService) UpdateCluster(ref entity.ClusterRef, update ClusterUpdate) (entity.Cluster, error) {
+	cluster, err := s.getCluster(ref)
+	if err != nil {
+		return entity.Cluster{}, err
+	}
+
+	if update.Name != nil {
+		if err := validateClusterName(*update.Name); err != nil {
+			return entity.Cluster{}, err
+		}
+		cluster.Name = *update.Name
+	}
+	if update.Description != nil {
+		cluster.Description = *update.Description
+	}
+	if update.Environment != nil {
+		environment, err := normalizeEnvironment(*update.Environment)
+		if err != nil {
+			return entity.Cluster{}, err
+		}
+		cluster.Environment = environment
+	}
+	if update.Labels != nil {
+		cluster.Labels = update.Labels
+	}
+
+	if err := s.ClusterRepo.Update(cluster); err != nil {
+		return entity.Cluster{}, err
+	}
+
+	updated, err := s.ClusterRepo.GetByID(int(cluster.ID))
+	if err != nil {
+		return entity.Cluster{}, err
+	}
+	return withoutConfig(updated), nil
+}
+
+// UpdateClusterConfig 校验并替换集群的 kubeconfig，丢弃已缓存的客户端。
+// 旧的探测结果不再适用，未要求重新探测时会被清空
+func (s *ClusterService) UpdateClusterConfig(ref entity.ClusterRef, config string, probe ProbeOptions) (*entity.ClusterProbe, error) {
+	if config == "" {
+		return nil, fmt.Errorf("%w: config is required", ErrInvalidCluster)
+	}
+
+	parsed, err := parseKubeconfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterID, err := s.ResolveClusterID(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ClusterRepo.UpdateConfig(clusterID, config); err != nil {
+		return nil, err
+	}
+	s.Clients.Invalidate(clusterID)
+	s.Informers.Stop(clusterID)
+
+	var result *entity.ClusterProbe
+	if probe.Enabled {
+		result = probeCluster(parsed, probe.Namespace)
+	}
+	if err := s.ClusterRepo.UpdateProbe(clusterID, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ProbeCluster 使用已保存的 kubeconfig 重新探测集群并保存结果
+func (s *ClusterService) ProbeCluster(ref entity.ClusterRef, namespace string) (*entity.ClusterProbe, error) {
+	cluster, err := s.getCluster(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := parseKubeconfig(cluster.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	probe := probeCluster(parsed, namespace)
+	if err := s.ClusterRepo.UpdateProbe(int(cluster.ID), probe); err != nil {
+		return nil, err
+	}
+	return probe, nil
+}
+
+// DeleteCluster 删除集群，并丢弃已缓存的客户端和 informer
+func (s *ClusterService) DeleteCluster(ref entity.ClusterRef) error {
+	clusterID, err := s.ResolveClusterID(ref)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ClusterRepo.Delete(clusterID); err != nil {
+		return err
+	}
+	s.Clients.Invalidate(clusterID)
+	s.Informers.Stop(clusterID)
+	return nil
+}
+
+func (s *ClusterService) getCluster(ref entity.ClusterRef) (entity.Cluster, error) {
+	if ref.Name != "" {
+		return s.ClusterRepo.GetByName(ref.Name)
+	}
+	return s.ClusterRepo.GetByID(ref.ID)
+}
+
+func validateClusterName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCluster)
+	}
+	if len(name) > 63 || !clusterNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: name %q must consist of lower case alphanumeric characters or '-' and be at most 63 characters", ErrInvalidCluster, name)
+	}
+	// 纯数字的名称会与 ID 引用混淆
+	if entity.ParseClusterRef(name).Name == "" {
+		return fmt.Errorf("%w: name %q must not be purely numeric", ErrInvalidCluster, name)
+	}
+	return nil
+}
+
+func normalizeEnvironment(environment string) (string, error) {
+	switch environment {
+	case "":
+		return entity.EnvironmentDev, nil
+	case entity.EnvironmentDev, entity.EnvironmentStaging, entity.EnvironmentProd:
+		return environment, nil
+	default:
+		return "", fmt.Errorf("%w: environment must be one of %s, %s, %s", ErrInvalidCluster,
+			entity.EnvironmentDev, entity.EnvironmentStaging, entity.EnvironmentProd)
+	}
+}
+
+func matchLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// withoutConfig 去掉 kubeconfig，避免在接口响应中泄露集群凭据
+func withoutConfig(cluster entity.Cluster) entity.Cluster {
+	cluster.Config = ""
+	return cluster
+}
@@ -2,161 +2,62 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go_code/simplek8s/core/application/repository"
-	"go_code/simplek8s/core/entity"
 
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	// ErrInvalidRequest 请求参数不合法
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrTimeout 等待资源达到期望状态超时
+	ErrTimeout = errors.New("timed out")
 )
 
 type ClusterService struct {
 	ClusterRepo repository.ClusterRepo
+	Clients     *ClientManager
+	Informers   *InformerCache
 }
 
-func NewClusterService(clusterRepo repository.ClusterRepo) ClusterService {
-	return ClusterService{ClusterRepo: clusterRepo}
+func NewClusterService(clusterRepo repository.ClusterRepo, clients *ClientManager, informers *InformerCache) ClusterService {
+	return ClusterService{ClusterRepo: clusterRepo, Clients: clients, Informers: informers}
 }
 
-// AddCluster 添加新的集群信息
-func (s *ClusterService) AddCluster(cluster entity.Cluster) error {
-	_, err := s.ClusterRepo.Create(cluster)
-	return err
+// GetClientPoolStats 获取集群客户端池的统计信息
+func (s *ClusterService) GetClientPoolStats() ClientPoolStats {
+	return s.Clients.Stats()
 }
 
-// CreateDeployment 在指定集群上创建 Deployment
-func (s *ClusterService) CreateDeployment(clusterID int, deploymentYAML string) error {
-	// 从存储库中获取集群信息
-	cluster, err := s.ClusterRepo.GetByID(clusterID)
-	if err != nil {
-		return fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	// 从字符串创建 REST 配置
-	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(cluster.Config))
-	if err != nil {
-		return fmt.Errorf("failed to create rest config: %v", err)
-	}
-
-	// 创建动态客户端
-	dynamicClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create dynamic client: %v", err)
-	}
-
-	// 解析部署 YAML 字符串
-	deployment := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal([]byte(deploymentYAML), deployment); err != nil {
-		return fmt.Errorf("failed to unmarshal deployment YAML: %v", err)
-	}
-
-	// 获取 GVK 和 GVR
-	gvk := deployment.GroupVersionKind()
-	gvr := schema.GroupVersionResource{
-		Group:    gvk.Group,
-		Version:  gvk.Version,
-		Resource: "deployments",
-	}
-
-	// 获取命名空间
-	namespace := deployment.GetNamespace()
-	if namespace == "" {
-		namespace = "default"
-	}
-
-	// 创建 Deployment
-	_, err = dynamicClient.Resource(gvr).Namespace(namespace).Create(context.Background(), deployment, metav1.CreateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to create deployment: %v", err)
-	}
-
-	return nil
+// GetInformerCacheStats 获取所有集群 informer 缓存的同步状态
+func (s *ClusterService) GetInformerCacheStats() InformerCacheStats {
+	return s.Informers.Stats()
 }
 
-// UpdateDeployment 在指定集群上更新 Deployment
-func (s *ClusterService) UpdateDeployment(clusterID int, deploymentYAML string) error {
-	cluster, err := s.ClusterRepo.GetByID(clusterID)
-	if err != nil {
-		return fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	configBytes := []byte(cluster.Config)
-	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
-	if err != nil {
-		return fmt.Errorf("failed to create rest config: %v", err)
-	}
-
-	dynamicClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create dynamic client: %v", err)
-	}
-
-	// 将字符串形式的 YAML 解析为 Unstructured 对象
-	deployment := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal([]byte(deploymentYAML), deployment); err != nil {
-		return fmt.Errorf("failed to unmarshal deployment YAML: %v", err)
-	}
-
-	// 从 YAML 中解析出 namespace 和 deploymentName
-	namespace := deployment.GetNamespace()
-	if namespace == "" {
-		namespace = "default"
-	}
-	deploymentName := deployment.GetName()
-	if deploymentName == "" {
-		return fmt.Errorf("deployment name is required in the YAML")
-	}
-
-	gvk := deployment.GroupVersionKind()
-	gvr := schema.GroupVersionResource{
-		Group:    gvk.Group,
-		Version:  gvk.Version,
-		Resource: "deployments",
-	}
-
-	// 获取现有的 Deployment
-	existingDeployment, err := dynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), deploymentName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to get existing deployment: %v", err)
-	}
-
-	// 更新现有 Deployment 的 spec
-	existingDeployment.Object["spec"] = deployment.Object["spec"]
+// GetClusterCacheStatus 获取指定集群 informer 缓存的同步状态
+func (s *ClusterService) GetClusterCacheStatus(clusterID int) ClusterCacheStatus {
+	return s.Informers.Status(clusterID)
+}
 
-	// 更新 Deployment
-	_, err = dynamicClient.Resource(gvr).Namespace(namespace).Update(context.Background(), existingDeployment, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to update deployment: %v", err)
-	}
+// CreateDeployment 在指定集群上创建 Deployment，YAML 中可以附带 Service、ConfigMap 等依赖资源
+func (s *ClusterService) CreateDeployment(clusterID int, deploymentYAML string, opts CreateOptions) (*ManifestResult, error) {
+	return s.createManifest(clusterID, deploymentYAML, "Deployment", opts)
+}
 
-	return nil
+// UpdateDeployment 在指定集群上更新 Deployment，opts.Wait 为 true 时返回发布结果
+func (s *ClusterService) UpdateDeployment(clusterID int, deploymentYAML string, opts UpdateOptions) (*RolloutStatus, error) {
+	return s.updateResource(clusterID, deploymentYAML, "Deployment", opts)
 }
 
 // GetDeployment 获取指定集群的 Deployment
 func (s *ClusterService) GetDeployment(clusterID int, namespace, deploymentName string) (*appsv1.Deployment, error) {
-	// 从存储库中获取集群信息
-	cluster, err := s.ClusterRepo.GetByID(clusterID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	// 创建 REST 配置
-	configBytes := []byte(cluster.Config)
-	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create rest config: %v", err)
-	}
-
-	// 创建 Kubernetes 客户端
-	clientset, err := kubernetes.NewForConfig(config)
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create kubernetes client: %v", err)
+		return nil, err
 	}
 
 	if namespace == "" {
@@ -164,9 +65,9 @@ func (s *ClusterService) GetDeployment(clusterID int, namespace, deploymentName
 	}
 
 	// 获取 Deployment
-	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.Background(), deploymentName, metav1.GetOptions{})
+	deployment, err := s.reader(clients).getDeployment(context.Background(), namespace, deploymentName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get deployment: %v", err)
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
 	}
 
 	return deployment, nil
@@ -174,23 +75,10 @@ func (s *ClusterService) GetDeployment(clusterID int, namespace, deploymentName
 
 // DeleteDeployment 删除指定集群的 Deployment
 func (s *ClusterService) DeleteDeployment(clusterID int, namespace, deploymentName string) error {
-	// 从存储库中获取集群信息
-	cluster, err := s.ClusterRepo.GetByID(clusterID)
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
 	if err != nil {
-		return fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	// 创建 REST 配置
-	configBytes := []byte(cluster.Config)
-	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
-	if err != nil {
-		return fmt.Errorf("failed to create rest config: %v", err)
-	}
-
-	// 创建 Kubernetes 客户端
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create kubernetes client: %v", err)
+		return err
 	}
 
 	if namespace == "" {
@@ -198,135 +86,30 @@ func (s *ClusterService) DeleteDeployment(clusterID int, namespace, deploymentNa
 	}
 
 	// 删除 Deployment
-	err = clientset.AppsV1().Deployments(namespace).Delete(context.Background(), deploymentName, metav1.DeleteOptions{})
+	err = clients.Clientset.AppsV1().Deployments(namespace).Delete(context.Background(), deploymentName, metav1.DeleteOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to delete deployment: %v", err)
+		return fmt.Errorf("failed to delete deployment: %w", err)
 	}
 
 	return nil
 }
 
-// CreateStatefulSet 在指定集群上创建 StatefulSet
-func (s *ClusterService) CreateStatefulSet(clusterID int, statefulSetYAML string) error {
-	cluster, err := s.ClusterRepo.GetByID(clusterID)
-	if err != nil {
-		return fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	configBytes := []byte(cluster.Config)
-
-	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
-	if err != nil {
-		return fmt.Errorf("failed to create rest config: %v", err)
-	}
-
-	dynamicClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create dynamic client: %v", err)
-	}
-
-	statefulSet := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal([]byte(statefulSetYAML), statefulSet); err != nil {
-		return fmt.Errorf("failed to unmarshal statefulSet YAML: %v", err)
-	}
-
-	gvk := statefulSet.GroupVersionKind()
-	gvr := schema.GroupVersionResource{
-		Group:    gvk.Group,
-		Version:  gvk.Version,
-		Resource: "statefulsets",
-	}
-
-	namespace := statefulSet.GetNamespace()
-	if namespace == "" {
-		namespace = "default"
-	}
-
-	_, err = dynamicClient.Resource(gvr).Namespace(namespace).Create(context.Background(), statefulSet, metav1.CreateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to create statefulSet: %v", err)
-	}
-
-	return nil
+// CreateStatefulSet 在指定集群上创建 StatefulSet，YAML 中可以附带 Service、ConfigMap 等依赖资源
+func (s *ClusterService) CreateStatefulSet(clusterID int, statefulSetYAML string, opts CreateOptions) (*ManifestResult, error) {
+	return s.createManifest(clusterID, statefulSetYAML, "StatefulSet", opts)
 }
 
-// UpdateStatefulSet 在指定集群上更新 StatefulSet
-func (s *ClusterService) UpdateStatefulSet(clusterID int, statefulSetYAML string) error {
-	cluster, err := s.ClusterRepo.GetByID(clusterID)
-	if err != nil {
-		return fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	configBytes := []byte(cluster.Config)
-
-	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
-	if err != nil {
-		return fmt.Errorf("failed to create rest config: %v", err)
-	}
-
-	dynamicClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create dynamic client: %v", err)
-	}
-
-	statefulSet := &unstructured.Unstructured{}
-	if err := yaml.Unmarshal([]byte(statefulSetYAML), statefulSet); err != nil {
-		return fmt.Errorf("failed to unmarshal statefulSet YAML: %v", err)
-	}
-
-	// 从 YAML 中解析出 namespace 和 statefulSetName
-	namespace := statefulSet.GetNamespace()
-	if namespace == "" {
-		namespace = "default"
-	}
-	statefulSetName := statefulSet.GetName()
-	if statefulSetName == "" {
-		return fmt.Errorf("statefulSet name is required in the YAML")
-	}
-
-	gvk := statefulSet.GroupVersionKind()
-	gvr := schema.GroupVersionResource{
-		Group:    gvk.Group,
-		Version:  gvk.Version,
-		Resource: "statefulsets",
-	}
-
-	existingStatefulSet, err := dynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), statefulSetName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to get existing statefulSet: %v", err)
-	}
-
-	// 更新现有 StatefulSet 的 spec
-	existingStatefulSet.Object["spec"] = statefulSet.Object["spec"]
-
-	_, err = dynamicClient.Resource(gvr).Namespace(namespace).Update(context.Background(), existingStatefulSet, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to update statefulSet: %v", err)
-	}
-
-	return nil
+// UpdateStatefulSet 在指定集群上更新 StatefulSet，opts.Wait 为 true 时返回发布结果
+func (s *ClusterService) UpdateStatefulSet(clusterID int, statefulSetYAML string, opts UpdateOptions) (*RolloutStatus, error) {
+	return s.updateResource(clusterID, statefulSetYAML, "StatefulSet", opts)
 }
 
 // GetStatefulSet 获取指定集群的 StatefulSet
 func (s *ClusterService) GetStatefulSet(clusterID int, namespace, statefulSetName string) (*appsv1.StatefulSet, error) {
-	// 从存储库中获取集群信息
-	cluster, err := s.ClusterRepo.GetByID(clusterID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	configBytes := []byte(cluster.Config)
-
-	// 从配置文件创建 REST 配置
-	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create rest config: %v", err)
-	}
-
-	// 创建 Kubernetes 客户端集
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create kubernetes clientset: %v", err)
+		return nil, err
 	}
 
 	if namespace == "" {
@@ -334,9 +117,9 @@ func (s *ClusterService) GetStatefulSet(clusterID int, namespace, statefulSetNam
 	}
 
 	// 获取 StatefulSet
-	statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(context.Background(), statefulSetName, metav1.GetOptions{})
+	statefulSet, err := s.reader(clients).getStatefulSet(context.Background(), namespace, statefulSetName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get statefulSet: %v", err)
+		return nil, fmt.Errorf("failed to get statefulSet: %w", err)
 	}
 
 	return statefulSet, nil
@@ -344,23 +127,10 @@ func (s *ClusterService) GetStatefulSet(clusterID int, namespace, statefulSetNam
 
 // DeleteStatefulSet 删除指定集群的 StatefulSet
 func (s *ClusterService) DeleteStatefulSet(clusterID int, namespace, statefulSetName string) error {
-	// 从存储库中获取集群信息
-	cluster, err := s.ClusterRepo.GetByID(clusterID)
-	if err != nil {
-		return fmt.Errorf("failed to get cluster: %v", err)
-	}
-
-	// 创建 REST 配置
-	configBytes := []byte(cluster.Config)
-	config, err := clientcmd.RESTConfigFromKubeConfig(configBytes)
-	if err != nil {
-		return fmt.Errorf("failed to create rest config: %v", err)
-	}
-
-	// 创建 Kubernetes 客户端
-	clientset, err := kubernetes.NewForConfig(config)
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
 	if err != nil {
-		return fmt.Errorf("failed to create kubernetes client: %v", err)
+		return err
 	}
 
 	if namespace == "" {
@@ -368,9 +138,9 @@ func (s *ClusterService) DeleteStatefulSet(clusterID int, namespace, statefulSet
 	}
 
 	// 删除 StatefulSet
-	err = clientset.AppsV1().StatefulSets(namespace).Delete(context.Background(), statefulSetName, metav1.DeleteOptions{})
+	err = clients.Clientset.AppsV1().StatefulSets(namespace).Delete(context.Background(), statefulSetName, metav1.DeleteOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to delete statefulSet: %v", err)
+		return fmt.Errorf("failed to delete statefulSet: %w", err)
 	}
 
 	return nil
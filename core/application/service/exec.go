@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+// ExecOptions 在容器中执行命令的参数
+type ExecOptions struct {
+	// Container 容器名称，Pod 只有一个容器时可以为空
+	Container string
+	// Command 要执行的命令，为空时使用 /bin/sh
+	Command []string
+	// TTY 分配终端，开启后 stderr 合并到 stdout
+	TTY   bool
+	Stdin bool
+}
+
+// TerminalSize 终端窗口大小
+type TerminalSize struct {
+	Cols uint16 `json:"cols"`
+	Rows uint16 `json:"rows"`
+}
+
+// ExecStreams 执行命令时的输入输出，Resize 在 TTY 模式下传递窗口大小的变化，关闭后停止调整
+type ExecStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Resize <-chan TerminalSize
+}
+
+// ExecSession 已校验的执行会话，在 Stream 之前不会建立与 kubelet 的连接
+type ExecSession struct {
+	Namespace string
+	Pod       string
+	Container string
+	opts      ExecOptions
+	executor  remotecommand.Executor
+}
+
+// PrepareExec 校验 Pod 和容器并创建执行会话，Pod 不存在或未运行等错误在升级 WebSocket 之前返回
+func (s *ClusterService) PrepareExec(ctx context.Context, clusterID int, namespace, pod string, opts ExecOptions) (*ExecSession, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+	if len(opts.Command) == 0 {
+		opts.Command = []string{"/bin/sh"}
+	}
+
+	target, err := clients.Clientset.CoreV1().Pods(namespace).Get(ctx, pod, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	if target.Status.Phase == corev1.PodSucceeded || target.Status.Phase == corev1.PodFailed {
+		return nil, fmt.Errorf("%w: cannot exec into pod %s in phase %s", ErrInvalidRequest, pod, target.Status.Phase)
+	}
+	container, err := execContainer(target, opts.Container)
+	if err != nil {
+		return nil, err
+	}
+	opts.Container = container
+
+	req := clients.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   opts.Command,
+			Stdin:     opts.Stdin,
+			Stdout:    true,
+			Stderr:    !opts.TTY,
+			TTY:       opts.TTY,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(clients.Config, "POST", req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create executor: %v", err)
+	}
+
+	return &ExecSession{
+		Namespace: namespace,
+		Pod:       pod,
+		Container: container,
+		opts:      opts,
+		executor:  executor,
+	}, nil
+}
+
+// Stream 执行命令直到命令退出或 ctx 被取消，返回命令的退出码
+func (e *ExecSession) Stream(ctx context.Context, streams ExecStreams) (int, error) {
+	options := remotecommand.StreamOptions{
+		Stdout: streams.Stdout,
+		Tty:    e.opts.TTY,
+	}
+	if e.opts.Stdin {
+		options.Stdin = streams.Stdin
+	}
+	if !e.opts.TTY {
+		options.Stderr = streams.Stderr
+	}
+	if e.opts.TTY && streams.Resize != nil {
+		options.TerminalSizeQueue = sizeQueue(streams.Resize)
+	}
+
+	err := e.executor.StreamWithContext(ctx, options)
+	var exitErr exec.CodeExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to exec in %s/%s: %w", e.Pod, e.Container, err)
+	}
+	return 0, nil
+}
+
+// execContainer 确定要执行命令的容器，未指定时 Pod 必须只有一个容器
+func execContainer(pod *corev1.Pod, container string) (string, error) {
+	if container == "" {
+		if len(pod.Spec.Containers) != 1 {
+			return "", fmt.Errorf("%w: pod %s has %d containers, a container must be specified", ErrInvalidRequest, pod.Name, len(pod.Spec.Containers))
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+
+	var statuses []corev1.ContainerStatus
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	statuses = append(statuses, pod.Status.EphemeralContainerStatuses...)
+	for _, status := range statuses {
+		if status.Name != container {
+			continue
+		}
+		if status.State.Running == nil {
+			return "", fmt.Errorf("%w: container %s in pod %s is not running", ErrInvalidRequest, container, pod.Name)
+		}
+		return container, nil
+	}
+
+	// 容器存在但还没有状态，通常是 Pod 尚未调度或正在拉取镜像
+	for _, c := range pod.Spec.Containers {
+		if c.Name == container {
+			return "", fmt.Errorf("%w: container %s in pod %s is not running", ErrInvalidRequest, container, pod.Name)
+		}
+	}
+	return "", fmt.Errorf("%w: container %s not found in pod %s", ErrInvalidRequest, container, pod.Name)
+}
+
+// sizeQueue 将窗口大小的 channel 适配为 remotecommand.TerminalSizeQueue
+type sizeQueue <-chan TerminalSize
+
+func (q sizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-q
+	if !ok {
+		return nil
+	}
+	return &remotecommand.TerminalSize{Width: size.Cols, Height: size.Rows}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExecContainer(t *testing.T) {
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+	pod := func(containers []string, statuses ...corev1.ContainerStatus) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0"}}
+		for _, name := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: name})
+		}
+		pod.Status.ContainerStatuses = statuses
+		return pod
+	}
+	tests := []struct {
+		name      string
+		pod       *corev1.Pod
+		container string
+		want      string
+		wantErr   bool
+	}{
+		{name: "single container default", pod: pod([]string{"web"}, corev1.ContainerStatus{Name: "web", State: running}), want: "web"},
+		{name: "explicit container", pod: pod([]string{"web", "proxy"}, corev1.ContainerStatus{Name: "web", State: running}, corev1.ContainerStatus{Name: "proxy", State: running}), container: "proxy", want: "proxy"},
+		// 多个容器时必须指定
+		{name: "ambiguous default", pod: pod([]string{"web", "proxy"}), wantErr: true},
+		{name: "not running", pod: pod([]string{"web"}, corev1.ContainerStatus{Name: "web", State: waiting}), wantErr: true},
+		{name: "no status yet", pod: pod([]string{"web"}), container: "web", wantErr: true},
+		{name: "unknown container", pod: pod([]string{"web"}, corev1.ContainerStatus{Name: "web", State: running}), container: "db", wantErr: true},
+		{
+			// 调试时添加的临时容器也可以执行命令
+			name: "ephemeral container",
+			pod: func() *corev1.Pod {
+				p := pod([]string{"web"}, corev1.ContainerStatus{Name: "web", State: running})
+				p.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{{Name: "debugger", State: running}}
+				return p
+			}(),
+			container: "debugger",
+			want:      "debugger",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := execContainer(tt.pod, tt.container)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRequest) {
+					t.Fatalf("expected ErrInvalidRequest, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// EnvInformerCache 设置为 true 时启用 informer 缓存，默认所有读取直接请求 API Server
+	EnvInformerCache = "SIMPLEK8S_INFORMER_CACHE"
+	// EnvInformerIdleTimeout 集群的 informer 在该时长内没有被读取时停止，格式如 10m
+	EnvInformerIdleTimeout = "SIMPLEK8S_INFORMER_IDLE_TIMEOUT"
+
+	defaultInformerIdleTimeout = 10 * time.Minute
+	// informerExpireInterval 检查空闲 informer 的间隔
+	informerExpireInterval = time.Minute
+	// accessReviewTimeout 启动 informer 前检查 list、watch 权限的超时时间
+	accessReviewTimeout = 10 * time.Second
+)
+
+// InformerCacheStats informer 缓存的统计信息
+type InformerCacheStats struct {
+	Enabled     bool                 `json:"enabled"`
+	IdleTimeout string               `json:"idleTimeout"`
+	Clusters    []ClusterCacheStatus `json:"clusters"`
+}
+
+// ClusterCacheStatus 单个集群的 informer 状态，Synced 表示所有资源都已完成首次同步
+type ClusterCacheStatus struct {
+	ClusterID int                    `json:"cluster_id"`
+	Started   bool                   `json:"started"`
+	Synced    bool                   `json:"synced"`
+	StartedAt *time.Time             `json:"startedAt,omitempty"`
+	LastUsed  *time.Time             `json:"lastUsed,omitempty"`
+	Resources []CachedResourceStatus `json:"resources,omitempty"`
+}
+
+// CachedResourceStatus 单种资源的同步状态和缓存的对象数量，Error 不为空时该资源没有缓存，读取直接请求 API Server
+type CachedResourceStatus struct {
+	Resource string `json:"resource"`
+	Synced   bool   `json:"synced"`
+	Count    int    `json:"count"`
+	Error    string `json:"error,omitempty"`
+}
+
+// InformerCache 按集群懒启动 Deployment、StatefulSet、Pod、ReplicaSet 和 Event 的 informer，
+// 只读接口在对应资源同步完成后从内存读取，长时间没有读取的集群会停止 informer 释放内存和 watch 连接。
+// 凭据没有跨命名空间 list、watch 权限的资源不启动 informer，始终直接请求 API Server
+type InformerCache struct {
+	enabled     bool
+	idleTimeout time.Duration
+
+	mu       sync.Mutex
+	clusters map[int]*clusterInformers
+}
+
+// clusterInformers 单个集群的 informer，与创建时使用的客户端绑定，客户端重建后需要重新启动
+type clusterInformers struct {
+	clients   *ClusterClients
+	factory   informers.SharedInformerFactory
+	stop      chan struct{}
+	startedAt time.Time
+	lastUsed  time.Time
+
+	// ready 在权限检查完成、informer 启动后关闭，之后 denied 不再修改
+	ready chan struct{}
+	// denied 没有 list、watch 权限而不缓存的资源及原因
+	denied map[string]string
+
+	deployments  appsinformers.DeploymentInformer
+	statefulSets appsinformers.StatefulSetInformer
+	replicaSets  appsinformers.ReplicaSetInformer
+	pods         coreinformers.PodInformer
+	events       coreinformers.EventInformer
+}
+
+// NewInformerCacheFromEnv 根据环境变量创建 informer 缓存，未启用时所有读取直接请求 API Server
+func NewInformerCacheFromEnv() (*InformerCache, error) {
+	enabled := false
+	if value := os.Getenv(EnvInformerCache); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", EnvInformerCache, err)
+		}
+		enabled = parsed
+	}
+
+	idleTimeout := defaultInformerIdleTimeout
+	if value := os.Getenv(EnvInformerIdleTimeout); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", EnvInformerIdleTimeout, value)
+		}
+		idleTimeout = parsed
+	}
+	return NewInformerCache(enabled, idleTimeout), nil
+}
+
+func NewInformerCache(enabled bool, idleTimeout time.Duration) *InformerCache {
+	c := &InformerCache{
+		enabled:     enabled,
+		idleTimeout: idleTimeout,
+		clusters:    make(map[int]*clusterInformers),
+	}
+	if enabled {
+		go c.expireIdle()
+	}
+	return c
+}
+
+// reader 返回集群的只读数据源，启用缓存时按需启动 informer，同步完成前的读取仍然请求 API Server
+func (c *InformerCache) reader(clients *ClusterClients) clusterReader {
+	live := liveReader{clientset: clients.Clientset}
+	if !c.enabled {
+		return live
+	}
+	return &cachedReader{live: live, informers: c.informersFor(clients)}
+}
+
+func (c *InformerCache) informersFor(clients *ClusterClients) *clusterInformers {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.clusters[clients.clusterID]; ok {
+		if current.clients == clients {
+			current.lastUsed = now
+			return current
+		}
+		// kubeconfig 变化后客户端被重建，旧的 informer 仍在使用旧的凭据
+		close(current.stop)
+	}
+
+	started := startInformers(clients, now)
+	c.clusters[clients.clusterID] = started
+	return started
+}
+
+// Stop 停止指定集群的 informer，集群配置被修改或删除后调用
+func (c *InformerCache) Stop(clusterID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.clusters[clusterID]; ok {
+		close(current.stop)
+		delete(c.clusters, clusterID)
+	}
+}
+
+// Stats 返回所有已启动 informer 的集群的状态
+func (c *InformerCache) Stats() InformerCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := InformerCacheStats{
+		Enabled:     c.enabled,
+		IdleTimeout: c.idleTimeout.String(),
+		Clusters:    make([]ClusterCacheStatus, 0, len(c.clusters)),
+	}
+	for clusterID, current := range c.clusters {
+		stats.Clusters = append(stats.Clusters, current.status(clusterID))
+	}
+	sort.Slice(stats.Clusters, func(i, j int) bool {
+		return stats.Clusters[i].ClusterID < stats.Clusters[j].ClusterID
+	})
+	return stats
+}
+
+// Status 返回指定集群的 informer 状态，尚未启动时 Started 为 false
+func (c *InformerCache) Status(clusterID int) ClusterCacheStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.clusters[clusterID]; ok {
+		return current.status(clusterID)
+	}
+	return ClusterCacheStatus{ClusterID: clusterID}
+}
+
+// expireIdle 定期停止超过 idleTimeout 没有被读取的集群的 informer
+func (c *InformerCache) expireIdle() {
+	ticker := time.NewTicker(informerExpireInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		c.mu.Lock()
+		for clusterID, current := range c.clusters {
+			if now.Sub(current.lastUsed) > c.idleTimeout {
+				close(current.stop)
+				delete(c.clusters, clusterID)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// startInformers 创建集群的 informer 并在后台检查权限后启动，不等待同步完成
+func startInformers(clients *ClusterClients, now time.Time) *clusterInformers {
+	// managedFields 占用大量内存且只读接口用不到
+	factory := informers.NewSharedInformerFactoryWithOptions(clients.Clientset, 0, informers.WithTransform(stripManagedFields))
+	started := &clusterInformers{
+		clients:      clients,
+		factory:      factory,
+		stop:         make(chan struct{}),
+		startedAt:    now,
+		lastUsed:     now,
+		ready:        make(chan struct{}),
+		denied:       make(map[string]string),
+		deployments:  factory.Apps().V1().Deployments(),
+		statefulSets: factory.Apps().V1().StatefulSets(),
+		replicaSets:  factory.Apps().V1().ReplicaSets(),
+		pods:         factory.Core().V1().Pods(),
+		events:       factory.Core().V1().Events(),
+	}
+	go started.start()
+	return started
+}
+
+// start 只为有跨命名空间 list、watch 权限的资源启动 informer。
+// 只有命名空间权限的凭据无法完成全局 informer 的同步，启动后会一直阻塞缓存就绪
+func (i *clusterInformers) start() {
+	defer close(i.ready)
+
+	for _, resource := range i.resources() {
+		if err := checkListWatch(i.clients, resource); err != nil {
+			i.denied[resource.name] = err.Error()
+			continue
+		}
+		// 工厂只启动已经请求过的 informer
+		resource.informer.Informer()
+	}
+	i.factory.Start(i.stop)
+}
+
+// checkListWatch 通过 SelfSubjectAccessReview 检查凭据能否在所有命名空间 list 和 watch 该资源
+func checkListWatch(clients *ClusterClients, resource cachedResource) error {
+	ctx, cancel := context.WithTimeout(context.Background(), accessReviewTimeout)
+	defer cancel()
+
+	for _, verb := range []string{"list", "watch"} {
+		review, err := clients.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:     verb,
+					Group:    resource.group,
+					Resource: resource.name,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to check %s permission: %v", verb, err)
+		}
+		if !review.Status.Allowed {
+			return fmt.Errorf("%s %s in all namespaces is not allowed", verb, resource.name)
+		}
+	}
+	return nil
+}
+
+// sharedInformer 各类型 informer 的公共部分，调用 Informer() 会在工厂中注册该 informer
+type sharedInformer interface {
+	Informer() cache.SharedIndexInformer
+}
+
+type cachedResource struct {
+	name     string
+	group    string
+	informer sharedInformer
+}
+
+func (i *clusterInformers) resources() []cachedResource {
+	return []cachedResource{
+		{name: "deployments", group: "apps", informer: i.deployments},
+		{name: "statefulsets", group: "apps", informer: i.statefulSets},
+		{name: "replicasets", group: "apps", informer: i.replicaSets},
+		{name: "pods", informer: i.pods},
+		{name: "events", informer: i.events},
+	}
+}
+
+// synced 返回资源是否可以从缓存读取，权限检查未完成、没有权限或尚未同步时返回 false
+func (i *clusterInformers) synced(resource string, informer sharedInformer) bool {
+	select {
+	case <-i.ready:
+	default:
+		return false
+	}
+	if _, denied := i.denied[resource]; denied {
+		return false
+	}
+	return informer.Informer().HasSynced()
+}
+
+func (i *clusterInformers) status(clusterID int) ClusterCacheStatus {
+	startedAt, lastUsed := i.startedAt, i.lastUsed
+	status := ClusterCacheStatus{
+		ClusterID: clusterID,
+		Started:   true,
+		StartedAt: &startedAt,
+		LastUsed:  &lastUsed,
+	}
+	select {
+	case <-i.ready:
+	default:
+		// 仍在检查权限
+		return status
+	}
+
+	// 没有权限的资源直接读取 API Server，不影响整体的同步状态
+	status.Synced = true
+	for _, resource := range i.resources() {
+		if reason, denied := i.denied[resource.name]; denied {
+			status.Resources = append(status.Resources, CachedResourceStatus{Resource: resource.name, Error: reason})
+			continue
+		}
+		informer := resource.informer.Informer()
+		synced := informer.HasSynced()
+		status.Resources = append(status.Resources, CachedResourceStatus{
+			Resource: resource.name,
+			Synced:   synced,
+			Count:    len(informer.GetStore().ListKeys()),
+		})
+		status.Synced = status.Synced && synced
+	}
+	return status
+}
+
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	// 删除事件中的对象可能是 DeletedFinalStateUnknown，原样返回
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestInformersFallBackWithoutListWatch(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "prod"}}
+	clientset := fake.NewSimpleClientset(pod)
+	// 凭据只有 Pod 的全局 list、watch 权限
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Resource == "pods"
+		return true, review, nil
+	})
+	clients := &ClusterClients{Clientset: clientset, clusterID: 1}
+
+	cache := NewInformerCache(true, time.Minute)
+	defer cache.Stop(1)
+	reader := cache.reader(clients)
+
+	var status ClusterCacheStatus
+	err := wait.PollUntilContextTimeout(context.Background(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		status = cache.Status(1)
+		return status.Synced, nil
+	})
+	if err != nil {
+		t.Fatalf("cache never synced: %+v", status)
+	}
+	for _, resource := range status.Resources {
+		if denied := resource.Resource != "pods"; denied != (resource.Error != "") {
+			t.Fatalf("unexpected status for %s: %+v", resource.Resource, resource)
+		}
+	}
+
+	// 没有权限的资源直接读取 API Server
+	cached := reader.(*cachedReader)
+	if cached.informers.synced("deployments", cached.informers.deployments) {
+		t.Fatal("deployments should not be served from the cache")
+	}
+	if !cached.informers.synced("pods", cached.informers.pods) {
+		t.Fatal("pods should be served from the cache")
+	}
+	pods, err := reader.listPods(context.Background(), "prod", labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 1 || pods[0].Name != "web-1" {
+		t.Fatalf("unexpected pods: %v", pods)
+	}
+	if _, err := reader.listDeployments(context.Background(), "prod", ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go_code/simplek8s/core/entity"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// probeTimeout 探测集群的总超时时间，避免不可达的集群阻塞注册请求
+const probeTimeout = 10 * time.Second
+
+// ProbeOptions 注册或更新集群时的探测参数
+type ProbeOptions struct {
+	// Enabled 为 true 时连接 API Server 获取版本、节点数和当前凭据的权限
+	Enabled bool
+	// Namespace 检查权限所针对的命名空间，为空时使用 default
+	Namespace string
+}
+
+// parsedKubeconfig 校验通过的 kubeconfig
+type parsedKubeconfig struct {
+	restConfig *rest.Config
+	context    string
+	server     string
+}
+
+// parseKubeconfig 解析并校验 kubeconfig，要求 current-context 指向存在的 cluster 和 user
+func parseKubeconfig(kubeconfig string) (*parsedKubeconfig, error) {
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse kubeconfig: %v", ErrInvalidCluster, err)
+	}
+
+	if config.CurrentContext == "" {
+		return nil, fmt.Errorf("%w: kubeconfig has no current-context", ErrInvalidCluster)
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("%w: current-context %q not found in kubeconfig", ErrInvalidCluster, config.CurrentContext)
+	}
+	cluster, ok := config.Clusters[kubeContext.Cluster]
+	if !ok {
+		return nil, fmt.Errorf("%w: cluster %q of context %q not found in kubeconfig", ErrInvalidCluster, kubeContext.Cluster, config.CurrentContext)
+	}
+	if cluster.Server == "" {
+		return nil, fmt.Errorf("%w: cluster %q has no server", ErrInvalidCluster, kubeContext.Cluster)
+	}
+	authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return nil, fmt.Errorf("%w: user %q of context %q not found in kubeconfig", ErrInvalidCluster, kubeContext.AuthInfo, config.CurrentContext)
+	}
+	if err := checkLocalReferences(kubeContext.Cluster, cluster, kubeContext.AuthInfo, authInfo); err != nil {
+		return nil, err
+	}
+
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid kubeconfig: %v", ErrInvalidCluster, err)
+	}
+
+	return &parsedKubeconfig{
+		restConfig: restConfig,
+		context:    config.CurrentContext,
+		server:     cluster.Server,
+	}, nil
+}
+
+// checkLocalReferences 拒绝需要在服务端执行命令或读取本地文件的凭据。kubeconfig 来自请求，
+// exec 和 auth-provider 会在本服务所在主机上执行命令，文件路径会读取本机文件，凭据必须内嵌在 kubeconfig 中
+func checkLocalReferences(clusterName string, cluster *clientcmdapi.Cluster, userName string, authInfo *clientcmdapi.AuthInfo) error {
+	if cluster.CertificateAuthority != "" {
+		return fmt.Errorf("%w: cluster %q references certificate-authority file, use certificate-authority-data", ErrInvalidCluster, clusterName)
+	}
+	switch {
+	case authInfo.Exec != nil:
+		return fmt.Errorf("%w: user %q uses an exec credential plugin, which is not allowed", ErrInvalidCluster, userName)
+	case authInfo.AuthProvider != nil:
+		return fmt.Errorf("%w: user %q uses an auth-provider, which is not allowed", ErrInvalidCluster, userName)
+	case authInfo.TokenFile != "":
+		return fmt.Errorf("%w: user %q references tokenFile, use token", ErrInvalidCluster, userName)
+	case authInfo.ClientCertificate != "":
+		return fmt.Errorf("%w: user %q references client-certificate file, use client-certificate-data", ErrInvalidCluster, userName)
+	case authInfo.ClientKey != "":
+		return fmt.Errorf("%w: user %q references client-key file, use client-key-data", ErrInvalidCluster, userName)
+	}
+	return nil
+}
+
+// probeCluster 连接 API Server 获取版本、节点数和当前凭据的权限，单项失败会记录在结果中而不会返回错误
+func probeCluster(parsed *parsedKubeconfig, namespace string) *entity.ClusterProbe {
+	if namespace == "" {
+		namespace = "default"
+	}
+	probe := &entity.ClusterProbe{
+		ProbedAt:  time.Now(),
+		Server:    parsed.server,
+		Context:   parsed.context,
+		Namespace: namespace,
+	}
+
+	config := rest.CopyConfig(parsed.restConfig)
+	config.Timeout = probeTimeout
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		probe.Error = fmt.Sprintf("failed to create kubernetes client: %v", err)
+		return probe
+	}
+
+	version, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		probe.Error = fmt.Sprintf("failed to get server version: %v", err)
+		return probe
+	}
+	probe.Reachable = true
+	probe.ServerVersion = version.GitVersion
+	probe.Platform = version.Platform
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	// ResourceVersion 为 0 时允许 API Server 从缓存返回，降低对大集群的压力
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		probe.NodeError = err.Error()
+	} else {
+		count := len(nodes.Items)
+		probe.NodeCount = &count
+	}
+
+	review, err := clientset.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		probe.PermissionError = err.Error()
+		return probe
+	}
+
+	for _, rule := range review.Status.ResourceRules {
+		probe.Permissions = append(probe.Permissions, entity.PermissionRule{
+			Verbs:         rule.Verbs,
+			APIGroups:     rule.APIGroups,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+		})
+	}
+	for _, rule := range review.Status.NonResourceRules {
+		probe.Permissions = append(probe.Permissions, entity.PermissionRule{
+			Verbs:           rule.Verbs,
+			NonResourceURLs: rule.NonResourceURLs,
+		})
+	}
+	probe.PermissionsIncomplete = review.Status.Incomplete
+	if review.Status.EvaluationError != "" {
+		probe.PermissionError = review.Status.EvaluationError
+	}
+
+	return probe
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testKubeconfig 生成只有一个 context 的 kubeconfig，cluster、user 为对应段落的内容
+func testKubeconfig(cluster, user string) string {
+	return `apiVersion: v1
+kind: Config
+current-context: prod
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+clusters:
+- name: prod
+  cluster:
+    server: https://10.0.0.1:6443
+` + cluster + `
+users:
+- name: admin
+  user:
+` + user + "\n"
+}
+
+func TestParseKubeconfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		kubeconfig string
+		wantErr    string
+	}{
+		{name: "embedded token", kubeconfig: testKubeconfig("    insecure-skip-tls-verify: true", "    token: abc")},
+		{name: "embedded certificates", kubeconfig: testKubeconfig("    certificate-authority-data: Y2E=", "    client-certificate-data: Y2VydA==\n    client-key-data: a2V5")},
+		{
+			name:       "exec plugin",
+			kubeconfig: testKubeconfig("", "    exec:\n      apiVersion: client.authentication.k8s.io/v1\n      command: /bin/sh\n      args: [\"-c\", \"id\"]"),
+			wantErr:    "exec credential plugin",
+		},
+		{
+			name:       "auth provider",
+			kubeconfig: testKubeconfig("", "    auth-provider:\n      name: oidc\n      config:\n        idp-issuer-url: https://issuer"),
+			wantErr:    "auth-provider",
+		},
+		{name: "token file", kubeconfig: testKubeconfig("", "    tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token"), wantErr: "tokenFile"},
+		{name: "client certificate file", kubeconfig: testKubeconfig("", "    client-certificate: /etc/kubernetes/pki/admin.crt\n    client-key-data: a2V5"), wantErr: "client-certificate file"},
+		{name: "client key file", kubeconfig: testKubeconfig("", "    client-certificate-data: Y2VydA==\n    client-key: /etc/kubernetes/pki/admin.key"), wantErr: "client-key file"},
+		{name: "certificate authority file", kubeconfig: testKubeconfig("    certificate-authority: /etc/kubernetes/pki/ca.crt", "    token: abc"), wantErr: "certificate-authority file"},
+		{name: "not yaml", kubeconfig: "{", wantErr: "failed to parse kubeconfig"},
+		{name: "no current context", kubeconfig: strings.Replace(testKubeconfig("", "    token: abc"), "current-context: prod", "", 1), wantErr: "no current-context"},
+		{name: "missing user", kubeconfig: strings.Replace(testKubeconfig("", "    token: abc"), "user: admin", "user: other", 1), wantErr: `user "other"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseKubeconfig(tt.kubeconfig)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidCluster) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected ErrInvalidCluster containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.server != "https://10.0.0.1:6443" || parsed.context != "prod" {
+				t.Fatalf("unexpected result %+v", parsed)
+			}
+		})
+	}
+}
+
+func TestNewClusterClientsRejectsExec(t *testing.T) {
+	_, err := newClusterClients(testKubeconfig("", "    exec:\n      apiVersion: client.authentication.k8s.io/v1\n      command: /bin/sh"))
+	if !errors.Is(err, ErrInvalidCluster) {
+		t.Fatalf("expected ErrInvalidCluster, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// ErrInvalidManifest 清单无法解析或缺少必要字段
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// installOrder 定义资源的安装顺序，被依赖的资源排在前面，未列出的类型（如 CR）排在最后
+var installOrder = []string{
+	"Namespace",
+	"ResourceQuota",
+	"LimitRange",
+	"CustomResourceDefinition",
+	"PriorityClass",
+	"StorageClass",
+	"PersistentVolume",
+	"ServiceAccount",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Secret",
+	"ConfigMap",
+	"PersistentVolumeClaim",
+	"Service",
+	"NetworkPolicy",
+	"DaemonSet",
+	"Pod",
+	"ReplicaSet",
+	"Deployment",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"HorizontalPodAutoscaler",
+	"PodDisruptionBudget",
+	"IngressClass",
+	"Ingress",
+}
+
+var installRank = func() map[string]int {
+	rank := make(map[string]int, len(installOrder))
+	for i, kind := range installOrder {
+		rank[kind] = i
+	}
+	return rank
+}()
+
+// ManifestResult 汇总一次清单操作中每个对象的结果
+type ManifestResult struct {
+	Total   int           `json:"total"`
+	Failed  int           `json:"failed"`
+	Results []ApplyResult `json:"results"`
+	// Rollouts 等待发布时清单中每个 Deployment、StatefulSet 的发布结果
+	Rollouts []RolloutStatus `json:"rollouts,omitempty"`
+}
+
+// manifestAction 对单个对象执行的操作，返回操作名称（created、configured、deleted 等）
+type manifestAction func(resource dynamic.ResourceInterface, obj *unstructured.Unstructured) (string, error)
+
+// splitManifest 将多文档 YAML（或 JSON）拆分为对象列表，空文档会被忽略，List 类型会被展开
+func splitManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+
+	var objs []*unstructured.Unstructured
+	for i := 0; ; i++ {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("%w: failed to decode document %d: %v", ErrInvalidManifest, i, err)
+		}
+		if isEmptyDocument(raw.Raw) {
+			continue
+		}
+
+		// 使用 UnstructuredJSONScheme 解析，整数保持为 int64，NestedInt64 才能读取 replicas、端口等字段
+		decoded, err := runtime.Decode(unstructured.UnstructuredJSONScheme, raw.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to decode document %d: %v", ErrInvalidManifest, i, err)
+		}
+		switch obj := decoded.(type) {
+		case *unstructured.UnstructuredList:
+			for j := range obj.Items {
+				objs = append(objs, &obj.Items[j])
+			}
+		case *unstructured.Unstructured:
+			objs = append(objs, obj)
+		default:
+			return nil, fmt.Errorf("%w: unexpected object %T in document %d", ErrInvalidManifest, decoded, i)
+		}
+	}
+
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("%w: no resources found in the YAML", ErrInvalidManifest)
+	}
+	return objs, nil
+}
+
+// isEmptyDocument 判断文档是否为空，只有注释或 --- 分隔符的文档解析后为 null 或 {}
+func isEmptyDocument(data []byte) bool {
+	trimmed := strings.TrimSpace(string(data))
+	return trimmed == "" || trimmed == "null" || trimmed == "{}"
+}
+
+// decodeSingle 解析只允许包含一个对象的 YAML，避免多余的文档被静默丢弃
+func decodeSingle(manifest string) (*unstructured.Unstructured, error) {
+	objs, err := splitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) > 1 {
+		return nil, fmt.Errorf("%w: expected a single resource in the YAML, got %d", ErrInvalidManifest, len(objs))
+	}
+	return objs[0], nil
+}
+
+// bindNamespace 对象未指定命名空间时使用 namespace，指定了不同的命名空间时返回错误
+func bindNamespace(obj *unstructured.Unstructured, namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(namespace)
+		return nil
+	}
+	if obj.GetNamespace() != namespace {
+		return fmt.Errorf("%w: namespace %q of %s %s does not match %q", ErrInvalidManifest,
+			obj.GetNamespace(), obj.GetKind(), obj.GetName(), namespace)
+	}
+	return nil
+}
+
+// bindName 对象未指定名称时使用 name，指定了不同的名称时返回错误
+func bindName(obj *unstructured.Unstructured, name string) error {
+	if name == "" {
+		return nil
+	}
+	if obj.GetName() == "" {
+		obj.SetName(name)
+		return nil
+	}
+	if obj.GetName() != name {
+		return fmt.Errorf("%w: name %q of %s does not match %q", ErrInvalidManifest, obj.GetName(), obj.GetKind(), name)
+	}
+	return nil
+}
+
+// sortForInstall 按安装顺序稳定排序，同类资源保持清单中的原有顺序
+func sortForInstall(objs []*unstructured.Unstructured) {
+	sort.SliceStable(objs, func(i, j int) bool {
+		return kindRank(objs[i].GetKind()) < kindRank(objs[j].GetKind())
+	})
+}
+
+// sortForUninstall 按安装顺序的逆序排序，用于删除
+func sortForUninstall(objs []*unstructured.Unstructured) {
+	sortForInstall(objs)
+	for i, j := 0, len(objs)-1; i < j; i, j = i+1, j-1 {
+		objs[i], objs[j] = objs[j], objs[i]
+	}
+}
+
+func kindRank(kind string) int {
+	if rank, ok := installRank[kind]; ok {
+		return rank
+	}
+	return len(installOrder)
+}
+
+// containsKind 判断清单中是否包含指定类型的资源
+func containsKind(objs []*unstructured.Unstructured, kind string) bool {
+	for _, obj := range objs {
+		if obj.GetKind() == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// runManifest 依次对每个对象执行操作，单个对象失败不会中断后续对象
+func runManifest(dynamicClient dynamic.Interface, mapper meta.ResettableRESTMapper, objs []*unstructured.Unstructured, action manifestAction) *ManifestResult {
+	result := &ManifestResult{Total: len(objs), Results: make([]ApplyResult, 0, len(objs))}
+
+	for _, obj := range objs {
+		item := ApplyResult{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+		}
+
+		operation, err := runAction(dynamicClient, mapper, obj, action)
+		item.Namespace = obj.GetNamespace()
+		if err != nil {
+			item.Operation = "failed"
+			item.Error = err.Error()
+			item.Reason = string(apierrors.ReasonForError(err))
+			var conflictErr *ApplyConflictError
+			if errors.As(err, &conflictErr) {
+				item.Conflicts = conflictErr.Conflicts
+			}
+			result.Failed++
+		} else {
+			item.Operation = operation
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	return result
+}
+
+func runAction(dynamicClient dynamic.Interface, mapper meta.ResettableRESTMapper, obj *unstructured.Unstructured, action manifestAction) (string, error) {
+	if obj.GetName() == "" {
+		return "", fmt.Errorf("%w: resource name is required", ErrInvalidManifest)
+	}
+
+	resource, err := resourceFor(dynamicClient, mapper, obj)
+	if err != nil {
+		return "", err
+	}
+
+	operation, err := action(resource, obj)
+	if err == nil && obj.GetKind() == "CustomResourceDefinition" {
+		// 新的 CRD 会改变可用的资源类型，让后续的 CR 能够被解析
+		mapper.Reset()
+	}
+	return operation, err
+}
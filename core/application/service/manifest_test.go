@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func objectNames(objs []*unstructured.Unstructured) []string {
+	names := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	return names
+}
+
+func TestSplitManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "single document",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+			want:     []string{"ConfigMap/app"},
+		},
+		{
+			name: "multiple documents",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n---\n" +
+				"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+			want: []string{"ConfigMap/app", "Deployment/web"},
+		},
+		{
+			name: "empty documents and comments",
+			manifest: "---\n# 注释\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: web\n---\n{}\n---\n" +
+				"apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n---\n",
+			want: []string{"Service/web", "Secret/db"},
+		},
+		{
+			name: "list is expanded",
+			manifest: "apiVersion: v1\nkind: List\nitems:\n" +
+				"- apiVersion: v1\n  kind: ServiceAccount\n  metadata:\n    name: a\n" +
+				"- apiVersion: v1\n  kind: ConfigMap\n  metadata:\n    name: b\n",
+			want: []string{"ServiceAccount/a", "ConfigMap/b"},
+		},
+		{
+			name:     "json",
+			manifest: `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"prod"}}`,
+			want:     []string{"Namespace/prod"},
+		},
+		{name: "only empty documents", manifest: "---\n---\n", wantErr: true},
+		{name: "invalid yaml", manifest: "kind: [ConfigMap\n", wantErr: true},
+		{name: "missing kind", manifest: "apiVersion: v1\nmetadata:\n  name: app\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := splitManifest(tt.manifest)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidManifest) {
+					t.Fatalf("expected ErrInvalidManifest, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := objectNames(objs); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitManifestIntegers(t *testing.T) {
+	objs, err := splitManifest("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 3\n" +
+		"  template:\n    spec:\n      containers:\n      - name: web\n        ports:\n        - containerPort: 8080\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replicas, found, err := unstructured.NestedInt64(objs[0].Object, "spec", "replicas")
+	if err != nil || !found || replicas != 3 {
+		t.Fatalf("expected replicas 3, got %d, found %v, err %v", replicas, found, err)
+	}
+	containers, _, _ := unstructured.NestedSlice(objs[0].Object, "spec", "template", "spec", "containers")
+	ports := containers[0].(map[string]interface{})["ports"].([]interface{})
+	if port := ports[0].(map[string]interface{})["containerPort"]; port != int64(8080) {
+		t.Fatalf("expected containerPort int64 8080, got %T %v", port, port)
+	}
+}
+
+func TestDecodeSingle(t *testing.T) {
+	if _, err := decodeSingle("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n"); !errors.Is(err, ErrInvalidManifest) {
+		t.Fatalf("expected ErrInvalidManifest for multiple documents, got %v", err)
+	}
+	obj, err := decodeSingle("---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetName() != "a" {
+		t.Fatalf("expected a, got %s", obj.GetName())
+	}
+}
+
+func TestSortForInstall(t *testing.T) {
+	newObj := func(kind, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind(kind)
+		obj.SetName(name)
+		return obj
+	}
+	tests := []struct {
+		name          string
+		objs          []*unstructured.Unstructured
+		wantInstall   []string
+		wantUninstall []string
+	}{
+		{
+			name: "dependencies first",
+			objs: []*unstructured.Unstructured{
+				newObj("Deployment", "web"), newObj("Service", "web"), newObj("ConfigMap", "app"),
+				newObj("Namespace", "prod"), newObj("CustomResourceDefinition", "rollouts.argoproj.io"),
+			},
+			wantInstall:   []string{"Namespace/prod", "CustomResourceDefinition/rollouts.argoproj.io", "ConfigMap/app", "Service/web", "Deployment/web"},
+			wantUninstall: []string{"Deployment/web", "Service/web", "ConfigMap/app", "CustomResourceDefinition/rollouts.argoproj.io", "Namespace/prod"},
+		},
+		{
+			name: "unknown kinds last and stable",
+			objs: []*unstructured.Unstructured{
+				newObj("Rollout", "b"), newObj("Ingress", "web"), newObj("Rollout", "a"), newObj("ConfigMap", "z"), newObj("ConfigMap", "y"),
+			},
+			wantInstall:   []string{"ConfigMap/z", "ConfigMap/y", "Ingress/web", "Rollout/b", "Rollout/a"},
+			wantUninstall: []string{"Rollout/a", "Rollout/b", "Ingress/web", "ConfigMap/y", "ConfigMap/z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			install := append([]*unstructured.Unstructured(nil), tt.objs...)
+			sortForInstall(install)
+			if got := objectNames(install); !reflect.DeepEqual(got, tt.wantInstall) {
+				t.Fatalf("install: expected %v, got %v", tt.wantInstall, got)
+			}
+			uninstall := append([]*unstructured.Unstructured(nil), tt.objs...)
+			sortForUninstall(uninstall)
+			if got := objectNames(uninstall); !reflect.DeepEqual(got, tt.wantUninstall) {
+				t.Fatalf("uninstall: expected %v, got %v", tt.wantUninstall, got)
+			}
+		})
+	}
+}
+
+func TestBindNamespaceAndName(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		objName   string
+		bindNS    string
+		bindName  string
+		wantNS    string
+		wantName  string
+		wantErr   bool
+	}{
+		{name: "fill missing", bindNS: "prod", bindName: "web", wantNS: "prod", wantName: "web"},
+		{name: "matching", namespace: "prod", objName: "web", bindNS: "prod", bindName: "web", wantNS: "prod", wantName: "web"},
+		{name: "nothing to bind", namespace: "dev", objName: "web", wantNS: "dev", wantName: "web"},
+		{name: "namespace mismatch", namespace: "dev", objName: "web", bindNS: "prod", wantErr: true},
+		{name: "name mismatch", namespace: "prod", objName: "api", bindNS: "prod", bindName: "web", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetKind("Deployment")
+			obj.SetNamespace(tt.namespace)
+			obj.SetName(tt.objName)
+			err := bindNamespace(obj, tt.bindNS)
+			if err == nil {
+				err = bindName(obj, tt.bindName)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidManifest) {
+					t.Fatalf("expected ErrInvalidManifest, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if obj.GetNamespace() != tt.wantNS || obj.GetName() != tt.wantName {
+				t.Fatalf("expected %s/%s, got %s/%s", tt.wantNS, tt.wantName, obj.GetNamespace(), obj.GetName())
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// patchTypes 补丁类型名称与 Kubernetes 补丁类型的对应关系
+var patchTypes = map[string]types.PatchType{
+	"json":      types.JSONPatchType,
+	"merge":     types.MergePatchType,
+	"strategic": types.StrategicMergePatchType,
+	"apply":     types.ApplyPatchType,
+}
+
+// PatchOptions 补丁参数
+type PatchOptions struct {
+	// Type 补丁类型：json（RFC 6902）、merge（RFC 7386）、strategic 或 apply（服务端 apply）
+	Type string
+	// FieldManager 字段管理者名称，apply 类型为空时使用 DefaultFieldManager
+	FieldManager string
+	// Force 强制接管与其他管理者冲突的字段，只用于 apply 类型
+	Force bool
+	// DryRun 为 true 时只在服务端校验并返回补丁后的对象，不写入集群
+	DryRun bool
+}
+
+// PatchResource 对资源应用补丁，返回补丁后的对象。resource 为资源的复数名称，CRD 使用 resource.group 形式，
+// 集群级资源忽略 namespace。补丁可以是 JSON 或 YAML，strategic 类型只支持 Kubernetes 内置资源
+func (s *ClusterService) PatchResource(clusterID int, namespace, resource, name string, patch []byte, opts PatchOptions) (*unstructured.Unstructured, error) {
+	patchType, patch, err := preparePatch(patch, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceClient, mapping, err := resourceByName(clients, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return patchObject(resourceClient, mapping.GroupVersionKind, name, patchType, patch, opts)
+}
+
+// preparePatch 校验补丁类型和参数，apply 以外的补丁从 YAML 转换为 JSON
+func preparePatch(patch []byte, opts PatchOptions) (types.PatchType, []byte, error) {
+	patchType, ok := patchTypes[opts.Type]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: invalid patch type %q, must be one of %s", ErrInvalidRequest, opts.Type, strings.Join(patchTypeNames(), ", "))
+	}
+	if opts.Force && patchType != types.ApplyPatchType {
+		return "", nil, fmt.Errorf("%w: force can only be used with apply patches", ErrInvalidRequest)
+	}
+	if len(strings.TrimSpace(string(patch))) == 0 {
+		return "", nil, fmt.Errorf("%w: patch is required", ErrInvalidRequest)
+	}
+	if patchType != types.ApplyPatchType {
+		// apply 补丁可以直接使用 YAML，其他类型需要 JSON
+		converted, err := yaml.YAMLToJSON(patch)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: invalid patch: %v", ErrInvalidRequest, err)
+		}
+		patch = converted
+	}
+	return patchType, patch, nil
+}
+
+// patchObject 对单个对象发送补丁，字段冲突时返回 ApplyConflictError
+func patchObject(resourceClient dynamic.ResourceInterface, gvk schema.GroupVersionKind, name string, patchType types.PatchType, patch []byte, opts PatchOptions) (*unstructured.Unstructured, error) {
+	kind := gvk.Kind
+	if patchType == types.StrategicMergePatchType && !scheme.Scheme.Recognizes(gvk) {
+		// 自定义资源没有 patchStrategy 元数据，API Server 会返回 415
+		return nil, fmt.Errorf("%w: strategic merge patch is not supported for %s, use merge or json", ErrInvalidRequest, kind)
+	}
+
+	patchOptions := metav1.PatchOptions{FieldManager: opts.FieldManager}
+	if patchType == types.ApplyPatchType {
+		patchOptions.FieldManager = ApplyOptions{FieldManager: opts.FieldManager}.fieldManager()
+		patchOptions.Force = &opts.Force
+	}
+	if opts.DryRun {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	patched, err := resourceClient.Patch(context.Background(), name, patchType, patch, patchOptions)
+	if err != nil {
+		target := &unstructured.Unstructured{}
+		target.SetKind(kind)
+		target.SetName(name)
+		if conflictErr := toApplyConflictError(target, err); conflictErr != nil {
+			return nil, conflictErr
+		}
+		return nil, fmt.Errorf("failed to patch %s %s: %w", kind, name, err)
+	}
+	return patched, nil
+}
+
+func patchTypeNames() []string {
+	names := make([]string, 0, len(patchTypes))
+	for name := range patchTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPreparePatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		patch     string
+		opts      PatchOptions
+		wantType  types.PatchType
+		wantPatch string
+		wantErr   bool
+	}{
+		{name: "merge json", patch: `{"data":{"key":"new"}}`, opts: PatchOptions{Type: "merge"}, wantType: types.MergePatchType, wantPatch: `{"data":{"key":"new"}}`},
+		{name: "merge yaml", patch: "data:\n  key: new\n", opts: PatchOptions{Type: "merge"}, wantType: types.MergePatchType, wantPatch: `{"data":{"key":"new"}}`},
+		{name: "json patch yaml", patch: "- op: remove\n  path: /data/key\n", opts: PatchOptions{Type: "json"}, wantType: types.JSONPatchType, wantPatch: `[{"op":"remove","path":"/data/key"}]`},
+		{name: "strategic", patch: `{"spec":{}}`, opts: PatchOptions{Type: "strategic"}, wantType: types.StrategicMergePatchType, wantPatch: `{"spec":{}}`},
+		// apply 补丁原样发送，API Server 接受 YAML
+		{name: "apply keeps yaml", patch: "data:\n  key: new\n", opts: PatchOptions{Type: "apply", Force: true}, wantType: types.ApplyPatchType, wantPatch: "data:\n  key: new\n"},
+		{name: "unknown type", patch: `{}`, opts: PatchOptions{Type: "replace"}, wantErr: true},
+		{name: "missing type", patch: `{}`, wantErr: true},
+		{name: "force without apply", patch: `{}`, opts: PatchOptions{Type: "merge", Force: true}, wantErr: true},
+		{name: "empty patch", patch: " \n", opts: PatchOptions{Type: "merge"}, wantErr: true},
+		{name: "invalid yaml", patch: "data: [", opts: PatchOptions{Type: "merge"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patchType, patch, err := preparePatch([]byte(tt.patch), tt.opts)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRequest) {
+					t.Fatalf("expected ErrInvalidRequest, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if patchType != tt.wantType || string(patch) != tt.wantPatch {
+				t.Fatalf("expected %s %s, got %s %s", tt.wantType, tt.wantPatch, patchType, patch)
+			}
+		})
+	}
+}
+
+func TestPatchObject(t *testing.T) {
+	configMapKind := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	widgetKind := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	conflict := &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    409,
+		Reason:  metav1.StatusReasonConflict,
+		Message: "Apply failed with 1 conflict: conflict with \"kubectl\": .data.key",
+		Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{
+			{Type: metav1.CauseTypeFieldManagerConflict, Field: ".data.key", Message: `conflict with "kubectl"`},
+		}},
+	}}
+
+	tests := []struct {
+		name      string
+		gvk       schema.GroupVersionKind
+		patchName string
+		patchType types.PatchType
+		patch     string
+		// reactorErr 不为空时 patch 请求返回该错误
+		reactorErr   error
+		want         string
+		wantErr      error
+		wantConflict bool
+		wantRequests int
+	}{
+		{name: "merge", gvk: configMapKind, patchName: "app", patchType: types.MergePatchType, patch: `{"data":{"key":"new"}}`, want: "new", wantRequests: 1},
+		{name: "json", gvk: configMapKind, patchName: "app", patchType: types.JSONPatchType, patch: `[{"op":"replace","path":"/data/key","value":"new"}]`, want: "new", wantRequests: 1},
+		// 自定义资源不支持 strategic merge patch，不发送请求
+		{name: "strategic on custom resource", gvk: widgetKind, patchName: "app", patchType: types.StrategicMergePatchType, patch: `{}`, wantErr: ErrInvalidRequest},
+		{name: "not found", gvk: configMapKind, patchName: "missing", patchType: types.MergePatchType, patch: `{}`, wantRequests: 1},
+		{name: "apply conflict", gvk: configMapKind, patchName: "app", patchType: types.ApplyPatchType, patch: "data:\n  key: new\n", reactorErr: conflict, wantConflict: true, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), testConfigMap("old"))
+			requests := 0
+			client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				requests++
+				if tt.reactorErr != nil {
+					return true, nil, tt.reactorErr
+				}
+				return false, nil, nil
+			})
+			resource := client.Resource(configMapsResource).Namespace("default")
+
+			patched, err := patchObject(resource, tt.gvk, tt.patchName, tt.patchType, []byte(tt.patch), PatchOptions{})
+			if requests != tt.wantRequests {
+				t.Fatalf("expected %d patch requests, got %d", tt.wantRequests, requests)
+			}
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			case tt.wantConflict:
+				var conflictErr *ApplyConflictError
+				if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].Manager != "kubectl" {
+					t.Fatalf("expected an apply conflict with kubectl, got %v", err)
+				}
+				return
+			case tt.want == "":
+				if !apierrors.IsNotFound(err) {
+					t.Fatalf("expected NotFound, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value, _, _ := unstructured.NestedString(patched.Object, "data", "key"); value != tt.want {
+				t.Fatalf("expected data.key %s, got %q", tt.want, value)
+			}
+			got, err := resource.Get(context.Background(), "app", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value, _, _ := unstructured.NestedString(got.Object, "data", "key"); value != tt.want {
+				t.Fatalf("expected stored data.key %s, got %q", tt.want, value)
+			}
+		})
+	}
+}
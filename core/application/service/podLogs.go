@@ -0,0 +1,259 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// maxLogStreams 一次请求最多同时读取的容器日志数，与 kubectl logs --max-log-requests 的作用一致
+	maxLogStreams = 20
+	// maxLogLineSize 单行日志的最大长度，超出部分作为新的一行返回
+	maxLogLineSize = 64 * 1024
+)
+
+// LogOptions 读取日志的参数
+type LogOptions struct {
+	// Container 容器名称，为空时读取 Pod 中的全部容器
+	Container string
+	// TailLines 只返回最后的行数，为 nil 时返回全部
+	TailLines *int64
+	// SinceSeconds 只返回最近若干秒的日志，为 nil 时不限制
+	SinceSeconds *int64
+	// Previous 读取容器上一次运行（重启前）的日志
+	Previous bool
+	// Follow 持续读取新的日志，直到客户端断开或容器退出
+	Follow bool
+	// Timestamps 在每行开头加上 RFC3339 时间戳
+	Timestamps bool
+}
+
+// LogLine 一行日志，Err 不为空表示该容器的日志流出错并已结束
+type LogLine struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Line      string `json:"line,omitempty"`
+	Err       string `json:"error,omitempty"`
+}
+
+// LogStream 一个或多个容器合并后的日志流
+type LogStream struct {
+	lines  chan LogLine
+	cancel context.CancelFunc
+}
+
+// Lines 返回日志行，所有容器的日志流结束后关闭
+func (s *LogStream) Lines() <-chan LogLine {
+	return s.lines
+}
+
+// Close 停止读取并释放所有日志流
+func (s *LogStream) Close() {
+	s.cancel()
+	// 丢弃尚未读取的行，让读取协程能够退出
+	for range s.lines {
+	}
+}
+
+// logSource 单个容器的日志
+type logSource struct {
+	pod       string
+	container string
+	stream    io.ReadCloser
+}
+
+// OpenPodLogs 打开单个 Pod 的日志流，容器不存在或未启动等错误在返回前就会报告
+func (s *ClusterService) OpenPodLogs(ctx context.Context, clusterID int, namespace, pod string, opts LogOptions) (*LogStream, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	target, err := clients.Clientset.CoreV1().Pods(namespace).Get(ctx, pod, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	containers, err := logContainers(target, opts.Container)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLogStreams(len(containers), opts); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	sources := make([]logSource, 0, len(containers))
+	for _, container := range containers {
+		stream, err := openLogStream(ctx, clients.Clientset, namespace, pod, container, opts)
+		if err != nil {
+			closeLogSources(sources)
+			cancel()
+			return nil, err
+		}
+		sources = append(sources, logSource{pod: pod, container: container, stream: stream})
+	}
+	return mergeLogSources(sources, nil, cancel), nil
+}
+
+// OpenWorkloadLogs 打开 Deployment 或 StatefulSet 控制的所有 Pod 的日志流。
+// 单个容器的日志打开失败时作为错误行返回，全部失败时返回第一个错误
+func (s *ClusterService) OpenWorkloadLogs(ctx context.Context, clusterID int, namespace, kind, name string, opts LogOptions) (*LogStream, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	pods, err := workloadPods(ctx, s.reader(clients), namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("%w: %s %s has no pods", ErrInvalidRequest, kind, name)
+	}
+
+	type podContainer struct{ pod, container string }
+	var targets []podContainer
+	for _, pod := range pods {
+		containers, err := logContainers(&pod.Pod, opts.Container)
+		if err != nil {
+			return nil, err
+		}
+		for _, container := range containers {
+			targets = append(targets, podContainer{pod: pod.Name, container: container})
+		}
+	}
+	if err := checkLogStreams(len(targets), opts); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var sources []logSource
+	var failures []LogLine
+	var firstErr error
+	for _, target := range targets {
+		stream, err := openLogStream(ctx, clients.Clientset, namespace, target.pod, target.container, opts)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failures = append(failures, LogLine{Pod: target.pod, Container: target.container, Err: err.Error()})
+			continue
+		}
+		sources = append(sources, logSource{pod: target.pod, container: target.container, stream: stream})
+	}
+	if len(sources) == 0 {
+		cancel()
+		return nil, firstErr
+	}
+	return mergeLogSources(sources, failures, cancel), nil
+}
+
+// logContainers 返回需要读取日志的容器，未指定时返回 Pod 中的全部容器
+func logContainers(pod *corev1.Pod, container string) ([]string, error) {
+	if container != "" {
+		for _, c := range pod.Spec.InitContainers {
+			if c.Name == container {
+				return []string{container}, nil
+			}
+		}
+		for _, c := range pod.Spec.Containers {
+			if c.Name == container {
+				return []string{container}, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: container %s not found in pod %s", ErrInvalidRequest, container, pod.Name)
+	}
+
+	containers := make([]string, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		containers = append(containers, c.Name)
+	}
+	return containers, nil
+}
+
+// checkLogStreams follow 模式下每个容器占用一个长连接，限制同时打开的数量
+func checkLogStreams(count int, opts LogOptions) error {
+	if opts.Follow && count > maxLogStreams {
+		return fmt.Errorf("%w: following %d log streams exceeds the limit of %d, specify a container or a pod",
+			ErrInvalidRequest, count, maxLogStreams)
+	}
+	return nil
+}
+
+func openLogStream(ctx context.Context, clientset kubernetes.Interface, namespace, pod, container string, opts LogOptions) (io.ReadCloser, error) {
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{
+		Container:    container,
+		Follow:       opts.Follow,
+		Previous:     opts.Previous,
+		SinceSeconds: opts.SinceSeconds,
+		TailLines:    opts.TailLines,
+		Timestamps:   opts.Timestamps,
+	}).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs of %s/%s: %w", pod, container, err)
+	}
+	return stream, nil
+}
+
+// mergeLogSources 每个容器一个协程按行读取，合并到同一个 channel
+func mergeLogSources(sources []logSource, failures []LogLine, cancel context.CancelFunc) *LogStream {
+	logStream := &LogStream{lines: make(chan LogLine), cancel: cancel}
+
+	var wg sync.WaitGroup
+	wg.Add(len(sources) + 1)
+	go func() {
+		defer wg.Done()
+		for _, failure := range failures {
+			logStream.lines <- failure
+		}
+	}()
+	for _, source := range sources {
+		go func(source logSource) {
+			defer wg.Done()
+			defer source.stream.Close()
+
+			reader := bufio.NewReaderSize(source.stream, maxLogLineSize)
+			for {
+				line, _, err := reader.ReadLine()
+				if err != nil {
+					if err != io.EOF && !errors.Is(err, context.Canceled) {
+						logStream.lines <- LogLine{Pod: source.pod, Container: source.container, Err: err.Error()}
+					}
+					return
+				}
+				logStream.lines <- LogLine{Pod: source.pod, Container: source.container, Line: string(line)}
+			}
+		}(source)
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(logStream.lines)
+	}()
+	return logStream
+}
+
+func closeLogSources(sources []logSource) {
+	for _, source := range sources {
+		source.stream.Close()
+	}
+}
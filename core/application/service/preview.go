@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+// lastAppliedAnnotation kubectl apply 记录的上次配置，内容是整个对象，不参与比较
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// FieldChange 单个字段的变化，Path 形如 spec.template.spec.containers[0].image，
+// Op 为 add、remove 或 replace，新增或删除整个子树时只记录子树的根
+type FieldChange struct {
+	Path string      `json:"path"`
+	Op   string      `json:"op"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// ResourcePreview 单个资源的预览结果，比较线上对象与服务端 dry-run 后将要保存的对象，
+// 忽略 status、managedFields、resourceVersion 等由服务端维护的字段
+type ResourcePreview struct {
+	ApplyResult
+	Changes []FieldChange `json:"changes"`
+	// Diff 统一格式的 YAML 文本差异
+	Diff string `json:"diff,omitempty"`
+	// Warnings API Server 在 dry-run 时返回的警告，如使用了废弃的 API 或字段
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ManifestPreview 清单中全部资源的预览结果
+type ManifestPreview struct {
+	Total   int               `json:"total"`
+	Failed  int               `json:"failed"`
+	Results []ResourcePreview `json:"results"`
+}
+
+// PreviewCreate 以服务端 dry-run 方式创建清单中的全部资源，返回将要创建的对象，不会写入集群。
+// 清单中新建的命名空间在 dry-run 时并不存在，其中的资源会预览失败
+func (s *ClusterService) PreviewCreate(clusterID int, manifest, kind string, opts CreateOptions) (*ManifestPreview, error) {
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := splitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if !containsKind(objs, kind) {
+		return nil, fmt.Errorf("%w: no %s found in the YAML", ErrInvalidManifest, kind)
+	}
+	for _, obj := range objs {
+		if err := bindNamespace(obj, opts.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	sortForInstall(objs)
+
+	client, err := newPreviewClient(clients)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ManifestPreview{Total: len(objs), Results: make([]ResourcePreview, 0, len(objs))}
+	for _, obj := range objs {
+		preview := client.previewCreate(clients.Mapper, obj)
+		if preview.Error != "" {
+			result.Failed++
+		}
+		result.Results = append(result.Results, preview)
+	}
+	return result, nil
+}
+
+// PreviewUpdate 以服务端 dry-run 方式执行与 updateResource 相同的更新，返回线上对象将要发生的变化，不会写入集群。
+// 结果的格式与 PreviewCreate 相同，dry-run 被拒绝（如校验失败、字段冲突）时记录在结果中
+func (s *ClusterService) PreviewUpdate(clusterID int, manifest, kind string, opts UpdateOptions) (*ManifestPreview, error) {
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := decodeResource(manifest, kind, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newPreviewClient(clients)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := resourceFor(client.dynamic, clients.Mapper, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := resource.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+	if err != nil && !(opts.ServerSide && apierrors.IsNotFound(err)) {
+		return nil, fmt.Errorf("failed to get existing %s: %w", kind, err)
+	}
+
+	var dryRun *unstructured.Unstructured
+	if opts.ServerSide {
+		// 服务端 apply 不接受 managedFields，resourceVersion 作为前置条件
+		obj.SetManagedFields(nil)
+		obj.SetResourceVersion(opts.ResourceVersion)
+		dryRun, err = resource.Apply(context.Background(), obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: opts.fieldManager(),
+			Force:        opts.Force,
+			DryRun:       []string{metav1.DryRunAll},
+		})
+		if conflictErr := toApplyConflictError(obj, err); conflictErr != nil {
+			err = conflictErr
+		}
+	} else {
+		updated := live.DeepCopy()
+		if opts.ResourceVersion != "" {
+			updated.SetResourceVersion(opts.ResourceVersion)
+		}
+		updated.Object["spec"] = obj.Object["spec"]
+		dryRun, err = resource.Update(context.Background(), updated, metav1.UpdateOptions{
+			DryRun: []string{metav1.DryRunAll},
+		})
+	}
+	if err != nil {
+		preview := ResourcePreview{ApplyResult: ApplyResult{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			Operation:  "failed",
+			Error:      fmt.Sprintf("failed to dry-run update of %s: %v", kind, err),
+			Reason:     string(apierrors.ReasonForError(err)),
+		}}
+		var conflictErr *ApplyConflictError
+		if errors.As(err, &conflictErr) {
+			preview.Conflicts = conflictErr.Conflicts
+		}
+		preview.Warnings = client.warnings.take()
+		return &ManifestPreview{Total: 1, Failed: 1, Results: []ResourcePreview{preview}}, nil
+	}
+
+	operation := "configured"
+	if live == nil {
+		operation = "created"
+	}
+	preview, err := buildPreview(obj, operation, live, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	preview.Warnings = client.warnings.take()
+	return &ManifestPreview{Total: 1, Results: []ResourcePreview{*preview}}, nil
+}
+
+// previewClient 带警告收集的动态客户端，复用集群客户端的连接池
+type previewClient struct {
+	dynamic  dynamic.Interface
+	warnings *warningCollector
+}
+
+func newPreviewClient(clients *ClusterClients) (*previewClient, error) {
+	warnings := &warningCollector{}
+	config := rest.CopyConfig(clients.Config)
+	config.WarningHandler = warnings
+	dynamicClient, err := dynamic.NewForConfigAndClient(config, clients.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+	return &previewClient{dynamic: dynamicClient, warnings: warnings}, nil
+}
+
+// previewCreate 以 dry-run 方式创建单个对象，失败时记录在结果中
+func (c *previewClient) previewCreate(mapper meta.ResettableRESTMapper, obj *unstructured.Unstructured) ResourcePreview {
+	preview := ResourcePreview{ApplyResult: ApplyResult{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+	}}
+	fail := func(err error) ResourcePreview {
+		preview.Namespace = obj.GetNamespace()
+		preview.Operation = "failed"
+		preview.Error = err.Error()
+		preview.Reason = string(apierrors.ReasonForError(err))
+		preview.Warnings = c.warnings.take()
+		return preview
+	}
+
+	if obj.GetName() == "" {
+		return fail(fmt.Errorf("%w: resource name is required", ErrInvalidManifest))
+	}
+	resource, err := resourceFor(c.dynamic, mapper, obj)
+	if err != nil {
+		return fail(err)
+	}
+	dryRun, err := resource.Create(context.Background(), obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	if err != nil {
+		return fail(fmt.Errorf("failed to dry-run create of %s %s: %w", obj.GetKind(), obj.GetName(), err))
+	}
+
+	built, err := buildPreview(obj, "created", nil, dryRun)
+	if err != nil {
+		return fail(err)
+	}
+	built.Warnings = c.warnings.take()
+	return *built
+}
+
+// buildPreview 比较线上对象和 dry-run 返回的对象，live 为空表示新建
+func buildPreview(obj *unstructured.Unstructured, operation string, live, dryRun *unstructured.Unstructured) (*ResourcePreview, error) {
+	from := previewFields(live)
+	to := previewFields(dryRun)
+
+	changes := []FieldChange{}
+	diffFields("", from, to, &changes)
+	if len(changes) == 0 {
+		operation = "unchanged"
+	}
+
+	diff, err := unifiedDiff(from, to, live != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourcePreview{
+		ApplyResult: ApplyResult{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			Operation:  operation,
+		},
+		Changes: changes,
+		Diff:    diff,
+	}, nil
+}
+
+// previewFields 去掉由服务端维护、每次写入都会变化的字段
+func previewFields(obj *unstructured.Unstructured) map[string]interface{} {
+	if obj == nil {
+		return map[string]interface{}{}
+	}
+	fields := obj.DeepCopy().Object
+	delete(fields, "status")
+	for _, name := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(fields, "metadata", name)
+	}
+	unstructured.RemoveNestedField(fields, "metadata", "annotations", lastAppliedAnnotation)
+	if annotations, found, _ := unstructured.NestedMap(fields, "metadata", "annotations"); found && len(annotations) == 0 {
+		unstructured.RemoveNestedField(fields, "metadata", "annotations")
+	}
+	return fields
+}
+
+// diffFields 递归比较两个对象，列表按下标比较
+func diffFields(path string, from, to interface{}, changes *[]FieldChange) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make([]string, 0, len(fromMap)+len(toMap))
+		for key := range fromMap {
+			keys = append(keys, key)
+		}
+		for key := range toMap {
+			if _, ok := fromMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fromValue, inFrom := fromMap[key]
+			toValue, inTo := toMap[key]
+			child := joinFieldPath(path, key)
+			switch {
+			case !inFrom:
+				*changes = append(*changes, FieldChange{Path: child, Op: "add", To: toValue})
+			case !inTo:
+				*changes = append(*changes, FieldChange{Path: child, Op: "remove", From: fromValue})
+			default:
+				diffFields(child, fromValue, toValue, changes)
+			}
+		}
+		return
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList {
+		for i := 0; i < len(fromList) || i < len(toList); i++ {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(fromList):
+				*changes = append(*changes, FieldChange{Path: child, Op: "add", To: toList[i]})
+			case i >= len(toList):
+				*changes = append(*changes, FieldChange{Path: child, Op: "remove", From: fromList[i]})
+			default:
+				diffFields(child, fromList[i], toList[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, FieldChange{Path: path, Op: "replace", From: from, To: to})
+	}
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// unifiedDiff 生成 YAML 的统一格式差异，新建时线上一侧为空
+func unifiedDiff(from, to map[string]interface{}, exists bool) (string, error) {
+	var fromLines []string
+	if exists {
+		fromYAML, err := yaml.Marshal(from)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal live object: %v", err)
+		}
+		fromLines = difflib.SplitLines(string(fromYAML))
+	}
+	toYAML, err := yaml.Marshal(to)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal dry-run object: %v", err)
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        fromLines,
+		B:        difflib.SplitLines(string(toYAML)),
+		FromFile: "live",
+		ToFile:   "dry-run",
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to diff objects: %v", err)
+	}
+	return diff, nil
+}
+
+// warningCollector 收集 API Server 通过 Warning 响应头返回的警告
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (c *warningCollector) HandleWarningHeader(code int, agent string, text string) {
+	// 只有 299 是 Kubernetes 使用的警告码
+	if code != 299 || strings.TrimSpace(text) == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, warning := range c.warnings {
+		if warning == text {
+			return
+		}
+	}
+	c.warnings = append(c.warnings, text)
+}
+
+// take 返回并清空已收集的警告
+func (c *warningCollector) take() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	warnings := c.warnings
+	c.warnings = nil
+	return warnings
+}
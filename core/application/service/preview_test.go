@@ -0,0 +1,178 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name string
+		from interface{}
+		to   interface{}
+		want []FieldChange
+	}{
+		{
+			name: "unchanged",
+			from: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}},
+			to:   map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}},
+			want: []FieldChange{},
+		},
+		{
+			name: "replace nested value",
+			from: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}},
+			to:   map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(3)}},
+			want: []FieldChange{{Path: "spec.replicas", Op: "replace", From: int64(2), To: int64(3)}},
+		},
+		{
+			// 新增或删除整个子树时只记录子树的根，按路径排序
+			name: "add and remove subtrees",
+			from: map[string]interface{}{"b": map[string]interface{}{"x": "1"}, "c": "same"},
+			to:   map[string]interface{}{"a": map[string]interface{}{"y": "2"}, "c": "same"},
+			want: []FieldChange{
+				{Path: "a", Op: "add", To: map[string]interface{}{"y": "2"}},
+				{Path: "b", Op: "remove", From: map[string]interface{}{"x": "1"}},
+			},
+		},
+		{
+			name: "list items by index",
+			from: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": "web:v1"},
+				map[string]interface{}{"name": "sidecar", "image": "proxy:v1"},
+			}},
+			to: map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "web", "image": "web:v2"},
+			}},
+			want: []FieldChange{
+				{Path: "containers[0].image", Op: "replace", From: "web:v1", To: "web:v2"},
+				{Path: "containers[1]", Op: "remove", From: map[string]interface{}{"name": "sidecar", "image": "proxy:v1"}},
+			},
+		},
+		{
+			name: "list grows",
+			from: map[string]interface{}{"args": []interface{}{"-v"}},
+			to:   map[string]interface{}{"args": []interface{}{"-v", "--debug"}},
+			want: []FieldChange{{Path: "args[1]", Op: "add", To: "--debug"}},
+		},
+		{
+			name: "type changes",
+			from: map[string]interface{}{"port": "http"},
+			to:   map[string]interface{}{"port": int64(80)},
+			want: []FieldChange{{Path: "port", Op: "replace", From: "http", To: int64(80)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := []FieldChange{}
+			diffFields("", tt.from, tt.to, &changes)
+			if !reflect.DeepEqual(changes, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, changes)
+			}
+		})
+	}
+}
+
+func TestPreviewFields(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  *unstructured.Unstructured
+		want map[string]interface{}
+	}{
+		{name: "nil object", want: map[string]interface{}{}},
+		{
+			name: "server fields removed",
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"kind": "Deployment",
+				"metadata": map[string]interface{}{
+					"name":              "web",
+					"uid":               "web-uid",
+					"resourceVersion":   "10",
+					"generation":        int64(2),
+					"creationTimestamp": "2024-01-01T00:00:00Z",
+					"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+					"annotations":       map[string]interface{}{lastAppliedAnnotation: "{}", "team": "web"},
+				},
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"replicas": int64(2)},
+			}},
+			want: map[string]interface{}{
+				"kind": "Deployment",
+				"metadata": map[string]interface{}{
+					"name":        "web",
+					"annotations": map[string]interface{}{"team": "web"},
+				},
+				"spec": map[string]interface{}{"replicas": int64(2)},
+			},
+		},
+		{
+			// 只有 last-applied 注解时整个 annotations 都不比较
+			name: "empty annotations removed",
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":        "web",
+					"annotations": map[string]interface{}{lastAppliedAnnotation: "{}"},
+				},
+			}},
+			want: map[string]interface{}{"metadata": map[string]interface{}{"name": "web"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var original *unstructured.Unstructured
+			if tt.obj != nil {
+				original = tt.obj.DeepCopy()
+			}
+			if got := previewFields(tt.obj); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			// 不能修改传入的对象
+			if tt.obj != nil && !reflect.DeepEqual(tt.obj, original) {
+				t.Fatal("previewFields modified its input")
+			}
+		})
+	}
+}
+
+func TestBuildPreview(t *testing.T) {
+	live := testConfigMap("old")
+	live.SetResourceVersion("1")
+	dryRun := testConfigMap("new")
+	dryRun.SetResourceVersion("2")
+
+	tests := []struct {
+		name      string
+		operation string
+		live      *unstructured.Unstructured
+		dryRun    *unstructured.Unstructured
+		want      string
+		wantDiff  string
+	}{
+		{name: "created", operation: "created", dryRun: dryRun, want: "created", wantDiff: "+  key: new"},
+		{name: "configured", operation: "configured", live: live, dryRun: dryRun, want: "configured", wantDiff: "-  key: old"},
+		// 只有 resourceVersion 不同时视为没有变化
+		{name: "unchanged", operation: "configured", live: live, dryRun: testConfigMap("old"), want: "unchanged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := buildPreview(dryRun, tt.operation, tt.live, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if preview.Operation != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, preview.Operation)
+			}
+			if tt.wantDiff == "" {
+				if preview.Diff != "" || len(preview.Changes) != 0 {
+					t.Fatalf("expected no changes, got %+v %q", preview.Changes, preview.Diff)
+				}
+				return
+			}
+			if !strings.Contains(preview.Diff, tt.wantDiff) {
+				t.Fatalf("expected diff to contain %q, got:\n%s", tt.wantDiff, preview.Diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// ApplyResult 描述单个资源的操作结果
+type ApplyResult struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Operation  string `json:"operation"`
+	Error      string `json:"error,omitempty"`
+	// Reason API Server 返回的失败原因，如 NotFound、Forbidden、Invalid
+	Reason    string          `json:"reason,omitempty"`
+	Conflicts []FieldConflict `json:"conflicts,omitempty"`
+}
+
+// ApplyManifest 按依赖顺序在指定集群上创建或更新清单中的全部资源，GVR 通过集群的 discovery 信息解析
+func (s *ClusterService) ApplyManifest(clusterID int, manifest string, opts ApplyOptions) (*ManifestResult, error) {
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := splitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	sortForInstall(objs)
+
+	action := applyObject
+	if opts.ServerSide {
+		action = serverSideApplyAction(opts)
+	}
+	return runManifest(clients.Dynamic, clients.Mapper, objs, action), nil
+}
+
+// DeleteManifest 按安装顺序的逆序删除清单中的全部资源
+func (s *ClusterService) DeleteManifest(clusterID int, manifest string) (*ManifestResult, error) {
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := splitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	sortForUninstall(objs)
+
+	return runManifest(clients.Dynamic, clients.Mapper, objs, deleteObject), nil
+}
+
+// CreateOptions 创建操作的可选参数
+type CreateOptions struct {
+	WaitOptions
+	// Namespace 不为空时清单中未指定命名空间的资源使用该命名空间，指定了其他命名空间的资源会被拒绝
+	Namespace string
+}
+
+// createManifest 按依赖顺序创建清单中的全部资源，清单中必须包含指定类型的资源
+func (s *ClusterService) createManifest(clusterID int, manifest, kind string, opts CreateOptions) (*ManifestResult, error) {
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := splitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if !containsKind(objs, kind) {
+		return nil, fmt.Errorf("%w: no %s found in the YAML", ErrInvalidManifest, kind)
+	}
+	for _, obj := range objs {
+		if err := bindNamespace(obj, opts.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	sortForInstall(objs)
+
+	result := runManifest(clients.Dynamic, clients.Mapper, objs, createObject)
+	if opts.Wait && result.Failed == 0 {
+		if result.Rollouts, err = waitForManifest(clients.Clientset, objs, opts.WaitOptions); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// UpdateOptions 更新操作的可选参数
+type UpdateOptions struct {
+	ApplyOptions
+	WaitOptions
+	// ResourceVersion 不为空时作为乐观锁前置条件，对象已被他人修改时直接返回冲突；
+	// 为空时遇到冲突会重新读取最新对象并按退避策略重试
+	ResourceVersion string
+	// Namespace、Name 不为空时 YAML 中的命名空间和名称必须与之一致，YAML 中未指定时使用该值
+	Namespace string
+	Name      string
+}
+
+// updateResource 更新单个指定类型的资源，需要等待时返回发布结果。默认只替换 spec，服务端 apply 模式下按字段所有权合并
+func (s *ClusterService) updateResource(clusterID int, manifest, kind string, opts UpdateOptions) (*RolloutStatus, error) {
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s.writeResource(clients, manifest, kind, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Wait {
+		return nil, nil
+	}
+	return waitForRollout(clients.Clientset, kind, obj.GetNamespace(), obj.GetName(), waitDeadline(opts.Timeout), opts.Events)
+}
+
+// writeResource 写入更新后的对象，返回解析出的对象，命名空间已按资源作用域填充
+func (s *ClusterService) writeResource(clients *ClusterClients, manifest, kind string, opts UpdateOptions) (*unstructured.Unstructured, error) {
+	obj, err := decodeResource(manifest, kind, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := resourceFor(clients.Dynamic, clients.Mapper, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ServerSide {
+		// 服务端 apply 会把对象中的 resourceVersion 作为前置条件
+		obj.SetResourceVersion(opts.ResourceVersion)
+		return obj, serverSideApply(resource, obj, opts.ApplyOptions)
+	}
+
+	replaceSpec := func() error {
+		existing, err := resource.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get existing %s: %w", kind, err)
+		}
+		if opts.ResourceVersion != "" {
+			existing.SetResourceVersion(opts.ResourceVersion)
+		}
+
+		// 替换现有对象的 spec
+		existing.Object["spec"] = obj.Object["spec"]
+
+		if _, err := resource.Update(context.Background(), existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s: %w", kind, err)
+		}
+		return nil
+	}
+
+	if opts.ResourceVersion != "" {
+		return obj, replaceSpec()
+	}
+	return obj, retry.RetryOnConflict(retry.DefaultBackoff, replaceSpec)
+}
+
+// decodeResource 解析更新请求中的单个对象，校验类型并按路径参数填充命名空间和名称
+func decodeResource(manifest, kind string, opts UpdateOptions) (*unstructured.Unstructured, error) {
+	obj, err := decodeSingle(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s YAML: %w", kind, err)
+	}
+	if obj.GetKind() != kind {
+		return nil, fmt.Errorf("%w: expected kind %s in the YAML, got %q", ErrInvalidManifest, kind, obj.GetKind())
+	}
+	if err := bindNamespace(obj, opts.Namespace); err != nil {
+		return nil, err
+	}
+	if err := bindName(obj, opts.Name); err != nil {
+		return nil, err
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("%w: %s name is required in the YAML", ErrInvalidManifest, kind)
+	}
+	return obj, nil
+}
+
+// createObject 创建对象
+func createObject(resource dynamic.ResourceInterface, obj *unstructured.Unstructured) (string, error) {
+	if _, err := resource.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return "created", nil
+}
+
+// applyObject 不存在则创建，存在则基于最新的 resourceVersion 整体更新，遇到冲突时重试
+func applyObject(resource dynamic.ResourceInterface, obj *unstructured.Unstructured) (string, error) {
+	operation := "configured"
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		existing, err := resource.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			operation, err = createObject(resource, obj)
+			if !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+			// 读取之后被并发创建，改为更新
+			operation = "configured"
+			existing, err = resource.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get existing %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		if _, err := resource.Update(context.Background(), obj, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return operation, nil
+}
+
+// deleteObject 删除对象，对象不存在时视为已删除
+func deleteObject(resource dynamic.ResourceInterface, obj *unstructured.Unstructured) (string, error) {
+	propagation := metav1.DeletePropagationBackground
+	err := resource.Delete(context.Background(), obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if apierrors.IsNotFound(err) {
+		return "notfound", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to delete %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return "deleted", nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var configMapsResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func testConfigMap(value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "app", "namespace": "default"},
+		"data":       map[string]interface{}{"key": value},
+	}}
+}
+
+func TestApplyObject(t *testing.T) {
+	tests := []struct {
+		name string
+		// existing 集群中已有的对象
+		existing []runtime.Object
+		// concurrentCreate 为 true 时模拟 Get 之后被其他请求创建
+		concurrentCreate bool
+		want             string
+	}{
+		{name: "create", want: "created"},
+		{name: "update", existing: []runtime.Object{testConfigMap("old")}, want: "configured"},
+		{name: "concurrent create", concurrentCreate: true, want: "configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), tt.existing...)
+			if tt.concurrentCreate {
+				client.PrependReactor("create", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if err := client.Tracker().Create(configMapsResource, testConfigMap("other"), "default"); err != nil {
+						t.Fatal(err)
+					}
+					return true, nil, apierrors.NewAlreadyExists(configMapsResource.GroupResource(), "app")
+				})
+			}
+			resource := client.Resource(configMapsResource).Namespace("default")
+
+			operation, err := applyObject(resource, testConfigMap("new"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if operation != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, operation)
+			}
+
+			got, err := resource.Get(context.Background(), "app", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value, _, _ := unstructured.NestedString(got.Object, "data", "key"); value != "new" {
+				t.Fatalf("expected data.key new, got %q", value)
+			}
+		})
+	}
+}
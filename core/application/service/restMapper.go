@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// resourceFor 通过 RESTMapper 将对象的 GVK 解析为 GVR，并根据资源作用域返回对应的动态客户端接口
+func resourceFor(dynamicClient dynamic.Interface, mapper meta.ResettableRESTMapper, obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return nil, fmt.Errorf("%w: apiVersion and kind are required", ErrInvalidManifest)
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// 可能是新注册的 CRD，重置缓存后重新发现一次
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve resource for %s: %w", gvk.String(), err)
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = "default"
+			obj.SetNamespace(namespace)
+		}
+		return dynamicClient.Resource(mapping.Resource).Namespace(namespace), nil
+	}
+
+	// 集群级资源不能携带 namespace
+	obj.SetNamespace("")
+	return dynamicClient.Resource(mapping.Resource), nil
+}
+
+// resourceByName 将路径中的资源名（如 deployments、rollouts.argoproj.io）解析为 GVR 和对应的动态客户端接口，
+// 集群级资源忽略 namespace
+func resourceByName(clients *ClusterClients, resource, namespace string) (dynamic.ResourceInterface, *meta.RESTMapping, error) {
+	mapping, err := resolveResource(clients, resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if namespace == "" {
+			namespace = "default"
+		}
+		return clients.Dynamic.Resource(mapping.Resource).Namespace(namespace), mapping, nil
+	}
+	return clients.Dynamic.Resource(mapping.Resource), mapping, nil
+}
+
+// resolveResource 将资源名解析为 RESTMapping，找不到时重置缓存后再发现一次
+func resolveResource(clients *ClusterClients, resource string) (*meta.RESTMapping, error) {
+	groupResource := schema.ParseGroupResource(resource)
+	if groupResource.Resource == "" {
+		return nil, fmt.Errorf("%w: resource is required", ErrInvalidRequest)
+	}
+
+	mapping, err := mappingForResource(clients.Mapper, groupResource)
+	if meta.IsNoMatchError(err) {
+		// 可能是新注册的 CRD，重置缓存后重新发现一次
+		clients.Mapper.Reset()
+		mapping, err = mappingForResource(clients.Mapper, groupResource)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve resource %s: %w", resource, err)
+	}
+	return mapping, nil
+}
+
+func mappingForResource(mapper meta.RESTMapper, groupResource schema.GroupResource) (*meta.RESTMapping, error) {
+	gvk, err := mapper.KindFor(groupResource.WithVersion(""))
+	if err != nil {
+		return nil, err
+	}
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
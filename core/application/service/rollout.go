@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// eventsTimeout 发布失败后读取事件的超时时间
+const eventsTimeout = 10 * time.Second
+
+// 发布结果
+const (
+	RolloutComplete    = "complete"
+	RolloutProgressing = "progressing"
+	RolloutFailed      = "failed"
+	RolloutTimeout     = "timeout"
+)
+
+// WaitOptions 创建或更新后等待发布完成的参数
+type WaitOptions struct {
+	// Wait 为 true 时等待 Deployment、StatefulSet 发布完成后再返回
+	Wait bool
+	// Timeout 等待的超时时间，为 0 时使用 defaultWaitTimeout
+	Timeout time.Duration
+	// Events 为 true 时，发布失败或超时的结果中附带工作负载相关的 Warning 事件
+	Events bool
+}
+
+// RolloutStatus 工作负载的发布状态，判断逻辑与 kubectl rollout status 一致
+type RolloutStatus struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Outcome 发布结果：complete、progressing、failed 或 timeout
+	Outcome string `json:"outcome"`
+	// Reason 发布失败的原因，如 ProgressDeadlineExceeded
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message"`
+	Generation         int64  `json:"generation"`
+	ObservedGeneration int64  `json:"observedGeneration"`
+	Replicas           int32  `json:"replicas"`
+	UpdatedReplicas    int32  `json:"updatedReplicas"`
+	ReadyReplicas      int32  `json:"readyReplicas"`
+	AvailableReplicas  int32  `json:"availableReplicas"`
+	CurrentRevision    string `json:"currentRevision,omitempty"`
+	UpdateRevision     string `json:"updateRevision,omitempty"`
+	// Warnings 发布失败或超时时工作负载相关的 Warning 事件，只在 WaitOptions.Events 为 true 时返回
+	Warnings []EventSummary `json:"warnings,omitempty"`
+}
+
+// RolloutOutcome 汇总清单中全部工作负载的发布结果，存在失败时为 failed，其次是 timeout，没有等待发布时为空
+func (r *ManifestResult) RolloutOutcome() string {
+	if len(r.Rollouts) == 0 {
+		return ""
+	}
+	outcome := RolloutComplete
+	for _, rollout := range r.Rollouts {
+		switch rollout.Outcome {
+		case RolloutFailed:
+			return RolloutFailed
+		case RolloutTimeout, RolloutProgressing:
+			outcome = RolloutTimeout
+		}
+	}
+	return outcome
+}
+
+// waitForManifest 依次等待清单中的 Deployment、StatefulSet 发布完成，所有对象共用同一个截止时间
+func waitForManifest(clientset kubernetes.Interface, objs []*unstructured.Unstructured, opts WaitOptions) ([]RolloutStatus, error) {
+	deadline := waitDeadline(opts.Timeout)
+	var rollouts []RolloutStatus
+	for _, obj := range objs {
+		if obj.GetKind() != "Deployment" && obj.GetKind() != "StatefulSet" {
+			continue
+		}
+		status, err := waitForRollout(clientset, obj.GetKind(), obj.GetNamespace(), obj.GetName(), deadline, opts.Events)
+		if err != nil {
+			return nil, err
+		}
+		rollouts = append(rollouts, *status)
+	}
+	return rollouts, nil
+}
+
+// GetRolloutStatus 获取 Deployment 或 StatefulSet 当前的发布状态
+func (s *ClusterService) GetRolloutStatus(clusterID int, namespace, kind, name string) (*RolloutStatus, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+	return rolloutStatus(context.Background(), clients.Clientset, kind, namespace, name)
+}
+
+// WaitForRollout 等待 Deployment 或 StatefulSet 发布完成、失败或超时，超时时返回最后一次的状态
+func (s *ClusterService) WaitForRollout(clusterID int, namespace, kind, name string, opts WaitOptions) (*RolloutStatus, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+	return waitForRollout(clients.Clientset, kind, namespace, name, waitDeadline(opts.Timeout), opts.Events)
+}
+
+// waitForRollout 轮询发布状态直到结束或到达截止时间，events 为 true 时发布失败或超时的结果附带 Warning 事件
+func waitForRollout(clientset kubernetes.Interface, kind, namespace, name string, deadline time.Time, events bool) (*RolloutStatus, error) {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	var status *RolloutStatus
+	err := wait.PollUntilContextCancel(ctx, waitPollInterval, true, func(ctx context.Context) (bool, error) {
+		current, err := rolloutStatus(ctx, clientset, kind, namespace, name)
+		if err != nil {
+			if ctx.Err() != nil {
+				// 截止时间到达时请求被取消，按超时处理
+				return false, nil
+			}
+			return false, err
+		}
+		status = current
+		return status.Outcome != RolloutProgressing, nil
+	})
+	if wait.Interrupted(err) {
+		if status == nil {
+			return nil, fmt.Errorf("%w waiting for rollout of %s %s", ErrTimeout, kind, name)
+		}
+		status.Outcome = RolloutTimeout
+		if events {
+			attachWarnings(clientset, status)
+		}
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if events && status.Outcome == RolloutFailed {
+		attachWarnings(clientset, status)
+	}
+	return status, nil
+}
+
+// attachWarnings 读取工作负载相关的 Warning 事件，读取失败时忽略，不影响发布结果
+func attachWarnings(clientset kubernetes.Interface, status *RolloutStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), eventsTimeout)
+	defer cancel()
+	warnings, err := workloadEvents(ctx, liveReader{clientset: clientset}, status.Namespace, status.Kind, status.Name, corev1.EventTypeWarning)
+	if err != nil {
+		return
+	}
+	status.Warnings = warnings
+}
+
+func rolloutStatus(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) (*RolloutStatus, error) {
+	switch kind {
+	case "Deployment":
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		return deploymentRolloutStatus(deployment), nil
+	case "StatefulSet":
+		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get statefulSet: %w", err)
+		}
+		return statefulSetRolloutStatus(statefulSet), nil
+	default:
+		return nil, fmt.Errorf("%w: rollout status is not supported for %s", ErrInvalidRequest, kind)
+	}
+}
+
+// deploymentRolloutStatus 与 kubectl 的 DeploymentStatusViewer 逻辑一致
+func deploymentRolloutStatus(deployment *appsv1.Deployment) *RolloutStatus {
+	status := &RolloutStatus{
+		Kind:               "Deployment",
+		Namespace:          deployment.Namespace,
+		Name:               deployment.Name,
+		Outcome:            RolloutProgressing,
+		Generation:         deployment.Generation,
+		ObservedGeneration: deployment.Status.ObservedGeneration,
+		Replicas:           deployment.Status.Replicas,
+		UpdatedReplicas:    deployment.Status.UpdatedReplicas,
+		ReadyReplicas:      deployment.Status.ReadyReplicas,
+		AvailableReplicas:  deployment.Status.AvailableReplicas,
+	}
+
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		status.Message = "Waiting for deployment spec update to be observed"
+		return status
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			status.Outcome = RolloutFailed
+			status.Reason = condition.Reason
+			status.Message = fmt.Sprintf("deployment %q exceeded its progress deadline: %s", deployment.Name, condition.Message)
+			return status
+		}
+	}
+
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	switch {
+	case deployment.Status.UpdatedReplicas < desired:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated",
+			deployment.Name, deployment.Status.UpdatedReplicas, desired)
+	case deployment.Status.Replicas > deployment.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination",
+			deployment.Name, deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
+	case deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available",
+			deployment.Name, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+	default:
+		status.Outcome = RolloutComplete
+		status.Message = fmt.Sprintf("deployment %q successfully rolled out", deployment.Name)
+	}
+	return status
+}
+
+// statefulSetRolloutStatus 与 kubectl 的 StatefulSetStatusViewer 逻辑一致，OnDelete 策略不跟踪发布进度
+func statefulSetRolloutStatus(statefulSet *appsv1.StatefulSet) *RolloutStatus {
+	status := &RolloutStatus{
+		Kind:               "StatefulSet",
+		Namespace:          statefulSet.Namespace,
+		Name:               statefulSet.Name,
+		Outcome:            RolloutProgressing,
+		Generation:         statefulSet.Generation,
+		ObservedGeneration: statefulSet.Status.ObservedGeneration,
+		Replicas:           statefulSet.Status.Replicas,
+		UpdatedReplicas:    statefulSet.Status.UpdatedReplicas,
+		ReadyReplicas:      statefulSet.Status.ReadyReplicas,
+		AvailableReplicas:  statefulSet.Status.AvailableReplicas,
+		CurrentRevision:    statefulSet.Status.CurrentRevision,
+		UpdateRevision:     statefulSet.Status.UpdateRevision,
+	}
+
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		status.Outcome = RolloutComplete
+		status.Message = fmt.Sprintf("statefulset %q uses the %s update strategy, rollout status is not tracked",
+			statefulSet.Name, statefulSet.Spec.UpdateStrategy.Type)
+		return status
+	}
+	if statefulSet.Status.ObservedGeneration == 0 || statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		status.Message = "Waiting for statefulset spec update to be observed"
+		return status
+	}
+
+	desired := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desired = *statefulSet.Spec.Replicas
+	}
+	if statefulSet.Status.ReadyReplicas < desired {
+		status.Message = fmt.Sprintf("Waiting for %d pods to be ready", desired-statefulSet.Status.ReadyReplicas)
+		return status
+	}
+
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		// 分区发布只更新序号不小于 partition 的 Pod
+		expected := desired - *rollingUpdate.Partition
+		if statefulSet.Status.UpdatedReplicas < expected {
+			status.Message = fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated",
+				statefulSet.Status.UpdatedReplicas, expected)
+			return status
+		}
+		status.Outcome = RolloutComplete
+		status.Message = fmt.Sprintf("partitioned roll out complete: %d new pods have been updated", statefulSet.Status.UpdatedReplicas)
+		return status
+	}
+
+	if statefulSet.Status.UpdateRevision != statefulSet.Status.CurrentRevision {
+		status.Message = fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s",
+			statefulSet.Status.UpdatedReplicas, statefulSet.Status.UpdateRevision)
+		return status
+	}
+	status.Outcome = RolloutComplete
+	status.Message = fmt.Sprintf("statefulset rolling update complete %d pods at revision %s",
+		statefulSet.Status.CurrentReplicas, statefulSet.Status.CurrentRevision)
+	return status
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// restartedAtAnnotation 与 kubectl rollout restart 使用相同的注解，修改 Pod 模板触发滚动重启
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// 发布操作
+const (
+	RolloutActionRestart = "restart"
+	RolloutActionPause   = "pause"
+	RolloutActionResume  = "resume"
+)
+
+// RolloutActionResult 重启、暂停、恢复发布的结果
+type RolloutActionResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Action    string `json:"action"`
+	// Skipped 资源已处于目标状态，没有执行修改
+	Skipped bool   `json:"skipped"`
+	Message string `json:"message"`
+	// RestartedAt 写入 Pod 模板的重启时间，只在 restart 时返回
+	RestartedAt string `json:"restartedAt,omitempty"`
+}
+
+// RestartRollout 通过修改 Pod 模板上的 restartedAt 注解滚动重启 Deployment、StatefulSet 或 DaemonSet，不修改其他配置
+func (s *ClusterService) RestartRollout(clusterID int, namespace, kind, name string) (*RolloutActionResult, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	restartedAt := time.Now().Format(time.RFC3339)
+	if err := restartWorkload(clients.Clientset, namespace, kind, name, restartedAt); err != nil {
+		return nil, err
+	}
+	return &RolloutActionResult{
+		Kind:        kind,
+		Namespace:   namespace,
+		Name:        name,
+		Action:      RolloutActionRestart,
+		Message:     fmt.Sprintf("%s %q restarted", kind, name),
+		RestartedAt: restartedAt,
+	}, nil
+}
+
+// restartWorkload 修改 Pod 模板的重启注解。暂停的 Deployment 不会发布新的 Pod 模板，与 kubectl 一致拒绝重启，
+// patch 以检查时读取到的 resourceVersion 作为前置条件，检查后被暂停或修改时重新检查
+func restartWorkload(clientset kubernetes.Interface, namespace, kind, name, restartedAt string) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		body := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"annotations": map[string]string{restartedAtAnnotation: restartedAt},
+					},
+				},
+			},
+		}
+		if kind == "Deployment" {
+			deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get deployment: %w", err)
+			}
+			if deployment.Spec.Paused {
+				return fmt.Errorf("%w: cannot restart paused deployment %s, resume it first", ErrInvalidRequest, name)
+			}
+			body["metadata"] = map[string]interface{}{"resourceVersion": deployment.ResourceVersion}
+		}
+
+		patch, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal patch: %v", err)
+		}
+		return patchWorkload(clientset, namespace, kind, name, patch)
+	})
+}
+
+// PauseRollout 暂停 Deployment 的发布，暂停期间对 Pod 模板的修改不会触发新的发布
+func (s *ClusterService) PauseRollout(clusterID int, namespace, name string) (*RolloutActionResult, error) {
+	return s.setPaused(clusterID, namespace, name, true)
+}
+
+// ResumeRollout 恢复已暂停的 Deployment 的发布
+func (s *ClusterService) ResumeRollout(clusterID int, namespace, name string) (*RolloutActionResult, error) {
+	return s.setPaused(clusterID, namespace, name, false)
+}
+
+func (s *ClusterService) setPaused(clusterID int, namespace, name string, paused bool) (*RolloutActionResult, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	result := &RolloutActionResult{Kind: "Deployment", Namespace: namespace, Name: name, Action: RolloutActionResume}
+	if paused {
+		result.Action = RolloutActionPause
+	}
+
+	deployment, err := clients.Clientset.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	if deployment.Spec.Paused == paused {
+		result.Skipped = true
+		if paused {
+			result.Message = fmt.Sprintf("deployment %q is already paused", name)
+		} else {
+			result.Message = fmt.Sprintf("deployment %q is not paused", name)
+		}
+		return result, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"paused": paused},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %v", err)
+	}
+	if err := patchWorkload(clients.Clientset, namespace, "Deployment", name, patch); err != nil {
+		return nil, err
+	}
+
+	if paused {
+		result.Message = fmt.Sprintf("deployment %q paused", name)
+	} else {
+		result.Message = fmt.Sprintf("deployment %q resumed", name)
+	}
+	return result, nil
+}
+
+// patchWorkload 以 strategic merge patch 修改工作负载，只修改 patch 中的字段
+func patchWorkload(clientset kubernetes.Interface, namespace, kind, name string, patch []byte) error {
+	var err error
+	switch kind {
+	case "Deployment":
+		_, err = clientset.AppsV1().Deployments(namespace).Patch(context.Background(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = clientset.AppsV1().StatefulSets(namespace).Patch(context.Background(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "DaemonSet":
+		_, err = clientset.AppsV1().DaemonSets(namespace).Patch(context.Background(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return fmt.Errorf("%w: rollout operations are not supported for %s", ErrInvalidRequest, kind)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to patch %s %s: %w", kind, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRestartWorkload(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		paused bool
+		// pauseBeforePatch 为 true 时模拟检查之后、patch 之前被其他请求暂停
+		pauseBeforePatch bool
+		wantErr          error
+	}{
+		{name: "deployment", kind: "Deployment"},
+		{name: "statefulset", kind: "StatefulSet"},
+		{name: "paused deployment", kind: "Deployment", paused: true, wantErr: ErrInvalidRequest},
+		{name: "paused after check", kind: "Deployment", pauseBeforePatch: true, wantErr: ErrInvalidRequest},
+		{name: "unsupported kind", kind: "Job", wantErr: ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", ResourceVersion: "1"},
+					Spec:       appsv1.DeploymentSpec{Paused: tt.paused},
+				},
+				&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", ResourceVersion: "1"}},
+			)
+			deployments := appsv1.SchemeGroupVersion.WithResource("deployments")
+			if tt.pauseBeforePatch {
+				clientset.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					if err := clientset.Tracker().Update(deployments, &appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", ResourceVersion: "2"},
+						Spec:       appsv1.DeploymentSpec{Paused: true},
+					}, "prod"); err != nil {
+						t.Fatal(err)
+					}
+					// fake 客户端不检查 patch 中的 resourceVersion，按 API Server 的行为返回冲突
+					var patch appsv1.Deployment
+					if err := json.Unmarshal(action.(k8stesting.PatchAction).GetPatch(), &patch); err != nil {
+						t.Fatal(err)
+					}
+					if patch.ResourceVersion != "2" {
+						return true, nil, apierrors.NewConflict(deployments.GroupResource(), "web", errors.New("the object has been modified"))
+					}
+					return false, nil, nil
+				})
+			}
+
+			err := restartWorkload(clientset, "prod", tt.kind, "web", "2026-01-01T00:00:00Z")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				deployment, err := clientset.AppsV1().Deployments("prod").Get(context.Background(), "web", metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := deployment.Spec.Template.Annotations[restartedAtAnnotation]; ok {
+					t.Fatal("paused deployment was restarted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			meta, template, err := workloadTemplate(clientset, "prod", tt.kind, "web")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := template.Annotations[restartedAtAnnotation]; got != "2026-01-01T00:00:00Z" {
+				t.Fatalf("expected restartedAt annotation on %s %s, got %q", tt.kind, meta.Name, got)
+			}
+		})
+	}
+}
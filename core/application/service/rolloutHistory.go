@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// revisionAnnotation Deployment 控制器记录在 ReplicaSet 上的版本号
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+	// changeCauseAnnotation 记录变更原因的注解，与 kubectl rollout history 中的 CHANGE-CAUSE 一致
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// Revision 工作负载的一个历史版本
+type Revision struct {
+	Revision    int64     `json:"revision"`
+	ChangeCause string    `json:"changeCause,omitempty"`
+	Images      []string  `json:"images"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// Source 保存该版本的 ReplicaSet 或 ControllerRevision 名称
+	Source string `json:"source"`
+	// Current 是否为当前使用的版本
+	Current bool `json:"current"`
+}
+
+// RevisionDiff 两个版本 Pod 模板的差异
+type RevisionDiff struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// Diff unified diff 格式的 Pod 模板差异，为空表示两个版本相同
+	Diff string `json:"diff"`
+}
+
+// UndoResult 回滚结果
+type UndoResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Revision 回滚到的版本
+	Revision int64 `json:"revision"`
+	// Skipped 当前版本已与目标版本一致，没有执行回滚
+	Skipped bool   `json:"skipped"`
+	Message string `json:"message"`
+}
+
+// revisionEntry 历史版本及其 Pod 模板
+type revisionEntry struct {
+	Revision
+	template corev1.PodTemplateSpec
+	// patch StatefulSet 的 ControllerRevision 中保存的 strategic merge patch
+	patch []byte
+}
+
+// ListRevisions 列出 Deployment（基于 ReplicaSet）或 StatefulSet（基于 ControllerRevision）的历史版本，按版本号升序
+func (s *ClusterService) ListRevisions(clusterID int, namespace, kind, name string) ([]Revision, error) {
+	entries, err := s.revisionHistory(clusterID, namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]Revision, 0, len(entries))
+	for _, entry := range entries {
+		revisions = append(revisions, entry.Revision)
+	}
+	return revisions, nil
+}
+
+// DiffRevisions 比较两个版本的 Pod 模板，to 为 0 时与当前版本比较
+func (s *ClusterService) DiffRevisions(clusterID int, namespace, kind, name string, from, to int64) (*RevisionDiff, error) {
+	entries, err := s.revisionHistory(clusterID, namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	fromEntry, err := findRevision(entries, from)
+	if err != nil {
+		return nil, err
+	}
+	toEntry, err := findRevision(entries, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromYAML, err := yaml.Marshal(fromEntry.template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal revision %d: %v", fromEntry.Revision.Revision, err)
+	}
+	toYAML, err := yaml.Marshal(toEntry.template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal revision %d: %v", toEntry.Revision.Revision, err)
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(fromYAML)),
+		B:        difflib.SplitLines(string(toYAML)),
+		FromFile: fmt.Sprintf("revision %d", fromEntry.Revision.Revision),
+		ToFile:   fmt.Sprintf("revision %d", toEntry.Revision.Revision),
+		Context:  3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff revisions: %v", err)
+	}
+
+	return &RevisionDiff{From: fromEntry.Revision.Revision, To: toEntry.Revision.Revision, Diff: diff}, nil
+}
+
+// UndoRollout 将 Pod 模板恢复为指定版本，revision 为 0 时回滚到上一个版本
+func (s *ClusterService) UndoRollout(clusterID int, namespace, kind, name string, revision int64) (*UndoResult, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	switch kind {
+	case "Deployment":
+		return undoDeployment(clients.Clientset, namespace, name, revision)
+	case "StatefulSet":
+		return undoStatefulSet(clients.Clientset, namespace, name, revision)
+	default:
+		return nil, fmt.Errorf("%w: rollout history is not supported for %s", ErrInvalidRequest, kind)
+	}
+}
+
+func (s *ClusterService) revisionHistory(clusterID int, namespace, kind, name string) ([]revisionEntry, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	switch kind {
+	case "Deployment":
+		deployment, err := clients.Clientset.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		return deploymentHistory(clients.Clientset, deployment)
+	case "StatefulSet":
+		statefulSet, err := clients.Clientset.AppsV1().StatefulSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get statefulSet: %w", err)
+		}
+		return statefulSetHistory(clients.Clientset, statefulSet)
+	default:
+		return nil, fmt.Errorf("%w: rollout history is not supported for %s", ErrInvalidRequest, kind)
+	}
+}
+
+// deploymentHistory 从 Deployment 拥有的 ReplicaSet 中读取历史版本
+func deploymentHistory(clientset kubernetes.Interface, deployment *appsv1.Deployment) ([]revisionEntry, error) {
+	replicaSets, err := ownedReplicaSets(context.Background(), liveReader{clientset: clientset}, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	current := deployment.Annotations[revisionAnnotation]
+	var entries []revisionEntry
+	for _, replicaSet := range replicaSets {
+		revision, err := strconv.ParseInt(replicaSet.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		template := *replicaSet.Spec.Template.DeepCopy()
+		// pod-template-hash 由控制器添加，不属于用户定义的模板
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		entries = append(entries, revisionEntry{
+			Revision: Revision{
+				Revision:    revision,
+				ChangeCause: replicaSet.Annotations[changeCauseAnnotation],
+				Images:      templateImages(template),
+				CreatedAt:   replicaSet.CreationTimestamp.Time,
+				Source:      replicaSet.Name,
+				Current:     replicaSet.Annotations[revisionAnnotation] == current,
+			},
+			template: template,
+		})
+	}
+
+	sortRevisions(entries)
+	return entries, nil
+}
+
+// ownedReplicaSets 列出由 Deployment 控制的 ReplicaSet
+func ownedReplicaSets(ctx context.Context, reader clusterReader, deployment *appsv1.Deployment) ([]appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of deployment %s: %v", deployment.Name, err)
+	}
+	replicaSets, err := reader.listReplicaSets(ctx, deployment.Namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicaSets: %w", err)
+	}
+
+	var owned []appsv1.ReplicaSet
+	for _, replicaSet := range replicaSets {
+		if metav1.IsControlledBy(&replicaSet, deployment) {
+			owned = append(owned, replicaSet)
+		}
+	}
+	return owned, nil
+}
+
+// statefulSetHistory 从 StatefulSet 拥有的 ControllerRevision 中读取历史版本
+func statefulSetHistory(clientset kubernetes.Interface, statefulSet *appsv1.StatefulSet) ([]revisionEntry, error) {
+	revisions, err := ownedControllerRevisions(context.Background(), liveReader{clientset: clientset}, statefulSet)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []revisionEntry
+	for _, revision := range revisions {
+		// ControllerRevision 中保存的是 {"spec":{"template":{...}}} 形式的 patch
+		var data struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(revision.Data.Raw, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode controllerRevision %s: %v", revision.Name, err)
+		}
+
+		entries = append(entries, revisionEntry{
+			Revision: Revision{
+				Revision:    revision.Revision,
+				ChangeCause: revision.Annotations[changeCauseAnnotation],
+				Images:      templateImages(data.Spec.Template),
+				CreatedAt:   revision.CreationTimestamp.Time,
+				Source:      revision.Name,
+				Current:     revision.Name == statefulSet.Status.UpdateRevision,
+			},
+			template: data.Spec.Template,
+			patch:    revision.Data.Raw,
+		})
+	}
+
+	sortRevisions(entries)
+	return entries, nil
+}
+
+// ownedControllerRevisions 列出由 StatefulSet 控制的 ControllerRevision
+func ownedControllerRevisions(ctx context.Context, reader clusterReader, statefulSet *appsv1.StatefulSet) ([]appsv1.ControllerRevision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(statefulSet.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of statefulSet %s: %v", statefulSet.Name, err)
+	}
+	revisions, err := reader.listControllerRevisions(ctx, statefulSet.Namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list controllerRevisions: %w", err)
+	}
+
+	var owned []appsv1.ControllerRevision
+	for _, revision := range revisions {
+		if metav1.IsControlledBy(&revision, statefulSet) {
+			owned = append(owned, revision)
+		}
+	}
+	return owned, nil
+}
+
+func undoDeployment(clientset kubernetes.Interface, namespace, name string, revision int64) (*UndoResult, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	if deployment.Spec.Paused {
+		return nil, fmt.Errorf("%w: cannot roll back paused deployment %s, resume it first", ErrInvalidRequest, name)
+	}
+
+	entries, err := deploymentHistory(clientset, deployment)
+	if err != nil {
+		return nil, err
+	}
+	target, err := undoTarget(entries, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UndoResult{Kind: "Deployment", Namespace: namespace, Name: name, Revision: target.Revision.Revision}
+	if equality.Semantic.DeepEqual(deployment.Spec.Template, target.template) {
+		result.Skipped = true
+		result.Message = fmt.Sprintf("skipped rollback (current template already matches revision %d)", target.Revision.Revision)
+		return result, nil
+	}
+
+	// 与 kubectl 一致使用 JSON patch 整体替换 Pod 模板，并以 resourceVersion 防止覆盖并发的修改
+	patch := []map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": deployment.ResourceVersion},
+		{"op": "replace", "path": "/spec/template", "value": target.template},
+	}
+	if target.ChangeCause != "" {
+		if deployment.Annotations == nil {
+			patch = append(patch, map[string]interface{}{
+				"op": "add", "path": "/metadata/annotations", "value": map[string]string{changeCauseAnnotation: target.ChangeCause},
+			})
+		} else {
+			patch = append(patch, map[string]interface{}{
+				"op": "add", "path": "/metadata/annotations/" + escapeJSONPointer(changeCauseAnnotation), "value": target.ChangeCause,
+			})
+		}
+	} else if _, ok := deployment.Annotations[changeCauseAnnotation]; ok {
+		// 目标版本没有变更原因时删除当前的注解，否则历史中回滚产生的版本会沿用错误的原因
+		patch = append(patch, map[string]interface{}{
+			"op": "remove", "path": "/metadata/annotations/" + escapeJSONPointer(changeCauseAnnotation),
+		})
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %v", err)
+	}
+
+	if _, err := clientset.AppsV1().Deployments(namespace).Patch(context.Background(), name, types.JSONPatchType, data, metav1.PatchOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+	result.Message = fmt.Sprintf("deployment %q rolled back to revision %d", name, target.Revision.Revision)
+	return result, nil
+}
+
+func undoStatefulSet(clientset kubernetes.Interface, namespace, name string, revision int64) (*UndoResult, error) {
+	statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statefulSet: %w", err)
+	}
+
+	entries, err := statefulSetHistory(clientset, statefulSet)
+	if err != nil {
+		return nil, err
+	}
+	target, err := undoTarget(entries, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UndoResult{Kind: "StatefulSet", Namespace: namespace, Name: name, Revision: target.Revision.Revision}
+	if target.Current {
+		result.Skipped = true
+		result.Message = fmt.Sprintf("skipped rollback (current template already matches revision %d)", target.Revision.Revision)
+		return result, nil
+	}
+
+	// ControllerRevision 中保存的 patch 可以直接恢复 Pod 模板，与 kubectl 的做法一致，
+	// 另外加上读取到的 resourceVersion 作为前置条件，防止覆盖并发的修改
+	var patch map[string]interface{}
+	if err := json.Unmarshal(target.patch, &patch); err != nil {
+		return nil, fmt.Errorf("failed to decode revision %d: %v", target.Revision.Revision, err)
+	}
+	metadata, _ := patch["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["resourceVersion"] = statefulSet.ResourceVersion
+	patch["metadata"] = metadata
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %v", err)
+	}
+
+	if _, err := clientset.AppsV1().StatefulSets(namespace).Patch(context.Background(), name, types.StrategicMergePatchType, data, metav1.PatchOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to roll back statefulSet: %w", err)
+	}
+	result.Message = fmt.Sprintf("statefulset %q rolled back to revision %d", name, target.Revision.Revision)
+	return result, nil
+}
+
+// undoTarget 选择回滚的目标版本，revision 为 0 时选择当前版本之前的最新版本
+func undoTarget(entries []revisionEntry, revision int64) (*revisionEntry, error) {
+	if revision != 0 {
+		return findRevision(entries, revision)
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].Current {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no previous revision to roll back to", ErrInvalidRequest)
+}
+
+// findRevision 查找指定版本，revision 为 0 时返回当前版本
+func findRevision(entries []revisionEntry, revision int64) (*revisionEntry, error) {
+	for i := range entries {
+		if (revision == 0 && entries[i].Current) || (revision != 0 && entries[i].Revision.Revision == revision) {
+			return &entries[i], nil
+		}
+	}
+	if revision == 0 {
+		return nil, fmt.Errorf("%w: current revision not found in history", ErrInvalidRequest)
+	}
+	return nil, fmt.Errorf("%w: revision %d not found in history", ErrInvalidRequest, revision)
+}
+
+func sortRevisions(entries []revisionEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Revision.Revision < entries[j].Revision.Revision
+	})
+}
+
+func templateImages(template corev1.PodTemplateSpec) []string {
+	images := make([]string, 0, len(template.Spec.Containers))
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// escapeJSONPointer 转义 JSON pointer 中的 ~ 和 /
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testRevisions(current int64, revisions ...int64) []revisionEntry {
+	entries := make([]revisionEntry, 0, len(revisions))
+	for _, revision := range revisions {
+		entries = append(entries, revisionEntry{Revision: Revision{Revision: revision, Current: revision == current}})
+	}
+	return entries
+}
+
+func TestUndoTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []revisionEntry
+		revision int64
+		want     int64
+		wantErr  bool
+	}{
+		{name: "previous revision", entries: testRevisions(3, 1, 2, 3), want: 2},
+		// 回滚后当前版本不一定是最大的版本号
+		{name: "current is not the latest", entries: testRevisions(3, 1, 3, 4), want: 4},
+		{name: "explicit revision", entries: testRevisions(3, 1, 2, 3), revision: 1, want: 1},
+		{name: "explicit current revision", entries: testRevisions(3, 1, 2, 3), revision: 3, want: 3},
+		{name: "unknown revision", entries: testRevisions(3, 1, 2, 3), revision: 5, wantErr: true},
+		{name: "only current revision", entries: testRevisions(1, 1), wantErr: true},
+		{name: "no history", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := undoTarget(tt.entries, tt.revision)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRequest) {
+					t.Fatalf("expected ErrInvalidRequest, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Revision.Revision != tt.want {
+				t.Fatalf("expected revision %d, got %d", tt.want, got.Revision.Revision)
+			}
+		})
+	}
+}
+
+func testReplicaSet(deployment *appsv1.Deployment, revision int64, image, changeCause string) *appsv1.ReplicaSet {
+	annotations := map[string]string{revisionAnnotation: strconv.FormatInt(revision, 10)}
+	if changeCause != "" {
+		annotations[changeCauseAnnotation] = changeCause
+	}
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            deployment.Name + "-" + strconv.FormatInt(revision, 10),
+			Namespace:       deployment.Namespace,
+			Labels:          deployment.Spec.Selector.MatchLabels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: testPodTemplate(image)},
+	}
+}
+
+func testPodTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+	}
+}
+
+func TestUndoDeploymentChangeCause(t *testing.T) {
+	tests := []struct {
+		name string
+		// current 当前版本的变更原因，target 回滚目标版本的变更原因
+		current string
+		target  string
+		// want 为空表示回滚后不应有变更原因注解
+		want string
+	}{
+		{name: "target has change cause", current: "update to v2", target: "deploy v1", want: "deploy v1"},
+		{name: "target has no change cause", current: "update to v2"},
+		{name: "neither has change cause"},
+		{name: "only target has change cause", target: "deploy v1", want: "deploy v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "web",
+					Namespace:       "prod",
+					UID:             types.UID("web-uid"),
+					ResourceVersion: "10",
+					Annotations:     map[string]string{revisionAnnotation: "2"},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					Template: testPodTemplate("web:v2"),
+				},
+			}
+			if tt.current != "" {
+				deployment.Annotations[changeCauseAnnotation] = tt.current
+			}
+			clientset := fake.NewSimpleClientset(deployment,
+				testReplicaSet(deployment, 1, "web:v1", tt.target),
+				testReplicaSet(deployment, 2, "web:v2", tt.current))
+
+			result, err := undoDeployment(clientset, "prod", "web", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Revision != 1 || result.Skipped {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+
+			got, err := clientset.AppsV1().Deployments("prod").Get(context.Background(), "web", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if image := got.Spec.Template.Spec.Containers[0].Image; image != "web:v1" {
+				t.Fatalf("expected image web:v1, got %s", image)
+			}
+			changeCause, found := got.Annotations[changeCauseAnnotation]
+			if changeCause != tt.want || found != (tt.want != "") {
+				t.Fatalf("expected change cause %q, got %q (found=%v)", tt.want, changeCause, found)
+			}
+		})
+	}
+}
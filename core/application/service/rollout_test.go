@@ -0,0 +1,204 @@
+package service
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		generation int64
+		replicas   *int32
+		status     appsv1.DeploymentStatus
+		want       string
+		wantReason string
+	}{
+		{
+			name:       "spec not observed",
+			generation: 2,
+			replicas:   int32Ptr(3),
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "progress deadline exceeded",
+			generation: 2,
+			replicas:   int32Ptr(3),
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded", Message: "ReplicaSet has timed out progressing"},
+			}},
+			want:       RolloutFailed,
+			wantReason: "ProgressDeadlineExceeded",
+		},
+		{
+			name:       "updating replicas",
+			generation: 2,
+			replicas:   int32Ptr(3),
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1},
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "old replicas pending termination",
+			generation: 2,
+			replicas:   int32Ptr(3),
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3},
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "updated replicas not available",
+			generation: 2,
+			replicas:   int32Ptr(3),
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2},
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "complete",
+			generation: 2,
+			replicas:   int32Ptr(3),
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			want:       RolloutComplete,
+		},
+		{
+			// 未设置 replicas 时默认为 1
+			name:       "default replicas",
+			generation: 1,
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 1},
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "scaled to zero",
+			generation: 1,
+			replicas:   int32Ptr(0),
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 1},
+			want:       RolloutComplete,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deploymentRolloutStatus(&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", Generation: tt.generation},
+				Spec:       appsv1.DeploymentSpec{Replicas: tt.replicas},
+				Status:     tt.status,
+			})
+			if got.Outcome != tt.want || got.Reason != tt.wantReason {
+				t.Fatalf("expected %s (%s), got %s (%s): %s", tt.want, tt.wantReason, got.Outcome, got.Reason, got.Message)
+			}
+		})
+	}
+}
+
+func TestStatefulSetRolloutStatus(t *testing.T) {
+	rollingUpdate := func(partition *int32) appsv1.StatefulSetUpdateStrategy {
+		strategy := appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+		if partition != nil {
+			strategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: partition}
+		}
+		return strategy
+	}
+	tests := []struct {
+		name       string
+		generation int64
+		strategy   appsv1.StatefulSetUpdateStrategy
+		status     appsv1.StatefulSetStatus
+		want       string
+	}{
+		{
+			name:       "on delete strategy",
+			generation: 2,
+			strategy:   appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+			want:       RolloutComplete,
+		},
+		{
+			name:       "never observed",
+			generation: 1,
+			strategy:   rollingUpdate(nil),
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "spec not observed",
+			generation: 2,
+			strategy:   rollingUpdate(nil),
+			status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, CurrentRevision: "web-1", UpdateRevision: "web-1"},
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "pods not ready",
+			generation: 2,
+			strategy:   rollingUpdate(nil),
+			status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 2, CurrentRevision: "web-2", UpdateRevision: "web-2"},
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "partition pending",
+			generation: 2,
+			strategy:   rollingUpdate(int32Ptr(1)),
+			status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "web-1", UpdateRevision: "web-2"},
+			want:       RolloutProgressing,
+		},
+		{
+			// 分区之前的 Pod 保持旧版本，revision 不一致也算完成
+			name:       "partition complete",
+			generation: 2,
+			strategy:   rollingUpdate(int32Ptr(1)),
+			status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 2, CurrentRevision: "web-1", UpdateRevision: "web-2"},
+			want:       RolloutComplete,
+		},
+		{
+			name:       "revision updating",
+			generation: 2,
+			strategy:   rollingUpdate(int32Ptr(0)),
+			status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, UpdatedReplicas: 2, CurrentRevision: "web-1", UpdateRevision: "web-2"},
+			want:       RolloutProgressing,
+		},
+		{
+			name:       "complete",
+			generation: 2,
+			strategy:   rollingUpdate(nil),
+			status:     appsv1.StatefulSetStatus{ObservedGeneration: 2, ReadyReplicas: 3, CurrentReplicas: 3, CurrentRevision: "web-2", UpdateRevision: "web-2"},
+			want:       RolloutComplete,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statefulSetRolloutStatus(&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", Generation: tt.generation},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3), UpdateStrategy: tt.strategy},
+				Status:     tt.status,
+			})
+			if got.Outcome != tt.want {
+				t.Fatalf("expected %s, got %s: %s", tt.want, got.Outcome, got.Message)
+			}
+		})
+	}
+}
+
+func TestRolloutOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []string
+		want     string
+	}{
+		{name: "not waited"},
+		{name: "complete", outcomes: []string{RolloutComplete, RolloutComplete}, want: RolloutComplete},
+		{name: "progressing counts as timeout", outcomes: []string{RolloutComplete, RolloutProgressing}, want: RolloutTimeout},
+		{name: "failed wins", outcomes: []string{RolloutTimeout, RolloutFailed, RolloutComplete}, want: RolloutFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ManifestResult{}
+			for _, outcome := range tt.outcomes {
+				result.Rollouts = append(result.Rollouts, RolloutStatus{Outcome: outcome})
+			}
+			if got := result.RolloutOutcome(); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// ScaleOptions 扩缩容参数
+type ScaleOptions struct {
+	// Replicas 目标副本数，以 + 或 - 开头时表示在当前副本数基础上增减，如 +2、-1
+	Replicas string
+	// Wait 为 true 时等待就绪副本数达到目标值后再返回
+	Wait bool
+	// Timeout 等待的超时时间，为 0 时使用 defaultWaitTimeout
+	Timeout time.Duration
+}
+
+// ScaleResult 扩缩容结果
+type ScaleResult struct {
+	Kind             string `json:"kind"`
+	Namespace        string `json:"namespace,omitempty"`
+	Name             string `json:"name"`
+	PreviousReplicas int64  `json:"previousReplicas"`
+	Replicas         int64  `json:"replicas"`
+	// ReadyReplicas 等待结束时的就绪副本数，只在 Wait 为 true 时返回
+	ReadyReplicas *int64 `json:"readyReplicas,omitempty"`
+	// TimedOut 为 true 时副本数已经修改，但等待就绪超时
+	TimedOut bool   `json:"timedOut,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// GetScale 通过 scale 子资源获取当前副本数，resource 为资源的复数名称，CRD 使用 resource.group 形式
+func (s *ClusterService) GetScale(clusterID int, namespace, resource, name string) (*ScaleResult, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceClient, mapping, err := resourceByName(clients, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	scale, err := resourceClient.Get(context.Background(), name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scale of %s %s: %w", mapping.GroupVersionKind.Kind, name, err)
+	}
+
+	replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	return &ScaleResult{
+		Kind:             mapping.GroupVersionKind.Kind,
+		Namespace:        scale.GetNamespace(),
+		Name:             name,
+		PreviousReplicas: replicas,
+		Replicas:         replicas,
+	}, nil
+}
+
+// Scale 通过 scale 子资源修改副本数，只修改副本数而不影响 spec 的其他字段，支持任何实现了 scale 子资源的资源
+func (s *ClusterService) Scale(clusterID int, namespace, resource, name string, opts ScaleOptions) (*ScaleResult, error) {
+	delta, relative, err := parseReplicas(opts.Replicas)
+	if err != nil {
+		return nil, err
+	}
+
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceClient, mapping, err := resourceByName(clients, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+	kind := mapping.GroupVersionKind.Kind
+
+	result := &ScaleResult{Kind: kind, Name: name}
+	// 相对值基于读取到的副本数计算，scale 对象携带 resourceVersion，被他人修改时重新读取并计算
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		scale, err := resourceClient.Get(context.Background(), name, metav1.GetOptions{}, "scale")
+		if err != nil {
+			return fmt.Errorf("failed to get scale of %s %s: %w", kind, name, err)
+		}
+
+		current, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+		target := delta
+		if relative {
+			target = current + delta
+		}
+		if target < 0 {
+			return fmt.Errorf("%w: replicas of %s %s would become %d", ErrInvalidRequest, kind, name, target)
+		}
+
+		if err := unstructured.SetNestedField(scale.Object, target, "spec", "replicas"); err != nil {
+			return err
+		}
+		if _, err := resourceClient.Update(context.Background(), scale, metav1.UpdateOptions{}, "scale"); err != nil {
+			return fmt.Errorf("failed to scale %s %s: %w", kind, name, err)
+		}
+
+		result.Namespace = scale.GetNamespace()
+		result.PreviousReplicas = current
+		result.Replicas = target
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 副本数已经修改，等待超时时仍然返回修改结果，由 TimedOut 标记
+	if opts.Wait {
+		ready, timedOut, err := waitForReadyReplicas(resourceClient, kind, name, result.Replicas, opts.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		result.ReadyReplicas = &ready
+		if timedOut {
+			result.TimedOut = true
+			result.Message = fmt.Sprintf("timed out waiting for %s %s to have %d ready replicas, got %d", kind, name, result.Replicas, ready)
+		}
+	}
+	return result, nil
+}
+
+// parseReplicas 解析副本数，返回数值以及是否为相对值
+func parseReplicas(value string) (int64, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false, fmt.Errorf("%w: replicas is required", ErrInvalidRequest)
+	}
+
+	relative := strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: invalid replicas %q", ErrInvalidRequest, value)
+	}
+	if !relative && replicas < 0 {
+		return 0, false, fmt.Errorf("%w: replicas must not be negative", ErrInvalidRequest)
+	}
+	return replicas, relative, nil
+}
+
+// waitForReadyReplicas 轮询直到控制器观察到最新的 spec 且就绪副本数等于目标值，返回就绪副本数以及是否超时。
+// 没有 status.readyReplicas 字段的 CRD 使用 scale 子资源的 status.replicas 判断
+func waitForReadyReplicas(resourceClient dynamic.ResourceInterface, kind, name string, replicas int64, timeout time.Duration) (int64, bool, error) {
+	if timeout <= 0 {
+		timeout = defaultWaitTimeout
+	}
+
+	var ready int64
+	err := wait.PollUntilContextTimeout(context.Background(), waitPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		obj, err := resourceClient.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+		}
+
+		observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+		if found && observed < obj.GetGeneration() {
+			return false, nil
+		}
+
+		readyReplicas, found, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		if !found && !isWorkloadKind(kind) {
+			scale, err := resourceClient.Get(ctx, name, metav1.GetOptions{}, "scale")
+			if err != nil {
+				return false, fmt.Errorf("failed to get scale of %s %s: %w", kind, name, err)
+			}
+			readyReplicas, _, _ = unstructured.NestedInt64(scale.Object, "status", "replicas")
+		}
+		ready = readyReplicas
+		return ready == replicas, nil
+	})
+	if wait.Interrupted(err) {
+		return ready, true, nil
+	}
+	if err != nil {
+		return ready, false, err
+	}
+	return ready, false, nil
+}
+
+// isWorkloadKind 内置工作负载在就绪副本数为 0 时会省略 status.readyReplicas
+func isWorkloadKind(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "ReplicaSet", "ReplicationController":
+		return true
+	}
+	return false
+}
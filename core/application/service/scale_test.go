@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestParseReplicas(t *testing.T) {
+	tests := []struct {
+		value    string
+		want     int64
+		relative bool
+		wantErr  bool
+	}{
+		{value: "3", want: 3},
+		{value: " 0 ", want: 0},
+		{value: "+2", want: 2, relative: true},
+		{value: "-1", want: -1, relative: true},
+		{value: "", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "1.5", wantErr: true},
+		{value: "+", wantErr: true},
+		// 超出 int32 范围
+		{value: "2147483648", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, relative, err := parseReplicas(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRequest) {
+					t.Fatalf("expected ErrInvalidRequest, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || relative != tt.relative {
+				t.Fatalf("expected %d relative=%v, got %d relative=%v", tt.want, tt.relative, got, relative)
+			}
+		})
+	}
+}
+
+func testDeployment(generation, observedGeneration int64, status map[string]interface{}) *unstructured.Unstructured {
+	status["observedGeneration"] = observedGeneration
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default", "generation": generation},
+		"status":     status,
+	}}
+}
+
+func TestWaitForReadyReplicas(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     []runtime.Object
+		replicas     int64
+		wantReady    int64
+		wantTimedOut bool
+		wantErr      bool
+	}{
+		{
+			name:      "ready",
+			existing:  []runtime.Object{testDeployment(2, 2, map[string]interface{}{"readyReplicas": int64(3)})},
+			replicas:  3,
+			wantReady: 3,
+		},
+		{
+			// 内置工作负载没有就绪副本时省略 readyReplicas
+			name:     "scaled to zero",
+			existing: []runtime.Object{testDeployment(2, 2, map[string]interface{}{})},
+			replicas: 0,
+		},
+		{
+			name:         "not ready",
+			existing:     []runtime.Object{testDeployment(2, 2, map[string]interface{}{"readyReplicas": int64(1)})},
+			replicas:     3,
+			wantReady:    1,
+			wantTimedOut: true,
+		},
+		{
+			// 控制器还没有观察到新的 spec，旧的就绪副本数不可信
+			name:         "stale status",
+			existing:     []runtime.Object{testDeployment(3, 2, map[string]interface{}{"readyReplicas": int64(3)})},
+			replicas:     3,
+			wantTimedOut: true,
+		},
+		{
+			name:     "not found",
+			replicas: 3,
+			wantErr:  true,
+		},
+	}
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{deployments: "DeploymentList"}, tt.existing...)
+			resource := client.Resource(deployments).Namespace("default")
+
+			// 超时时间短于轮询间隔，只检查一次
+			ready, timedOut, err := waitForReadyReplicas(resource, "Deployment", "web", tt.replicas, 10*time.Millisecond)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ready != tt.wantReady || timedOut != tt.wantTimedOut {
+				t.Fatalf("expected ready=%d timedOut=%v, got ready=%d timedOut=%v", tt.wantReady, tt.wantTimedOut, ready, timedOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// DefaultFieldManager 服务端 apply 时默认使用的字段管理者名称
+const DefaultFieldManager = "simplek8s"
+
+// ApplyOptions 控制资源的写入方式
+type ApplyOptions struct {
+	// ServerSide 为 true 时使用 Kubernetes 服务端 apply，只修改 YAML 中声明的字段
+	ServerSide bool
+	// FieldManager 服务端 apply 使用的字段管理者名称，为空时使用 DefaultFieldManager
+	FieldManager string
+	// Force 为 true 时强制接管与其他管理者冲突的字段
+	Force bool
+}
+
+func (o ApplyOptions) fieldManager() string {
+	if o.FieldManager == "" {
+		return DefaultFieldManager
+	}
+	return o.FieldManager
+}
+
+// FieldConflict 描述一个字段所有权冲突
+type FieldConflict struct {
+	Field   string `json:"field"`
+	Manager string `json:"manager,omitempty"`
+	Message string `json:"message"`
+}
+
+// ApplyConflictError 服务端 apply 与其他字段管理者冲突时返回的错误
+type ApplyConflictError struct {
+	Kind      string          `json:"kind"`
+	Name      string          `json:"name"`
+	Message   string          `json:"message"`
+	Conflicts []FieldConflict `json:"conflicts"`
+
+	// err 原始的 API 错误，保留以便调用方通过 apierrors 判断错误类型
+	err error
+}
+
+func (e *ApplyConflictError) Error() string {
+	fields := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		fields = append(fields, conflict.Field)
+	}
+	return fmt.Sprintf("apply of %s %s conflicts with other field managers on %s", e.Kind, e.Name, strings.Join(fields, ", "))
+}
+
+func (e *ApplyConflictError) Unwrap() error {
+	return e.err
+}
+
+// serverSideApply 使用服务端 apply 写入对象，字段冲突会被转换为 ApplyConflictError
+func serverSideApply(resource dynamic.ResourceInterface, obj *unstructured.Unstructured, opts ApplyOptions) error {
+	// 服务端 apply 不接受 managedFields
+	obj.SetManagedFields(nil)
+
+	_, err := resource.Apply(context.Background(), obj.GetName(), obj, metav1.ApplyOptions{
+		FieldManager: opts.fieldManager(),
+		Force:        opts.Force,
+	})
+	if err == nil {
+		return nil
+	}
+
+	if conflictErr := toApplyConflictError(obj, err); conflictErr != nil {
+		return conflictErr
+	}
+	return fmt.Errorf("failed to apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+}
+
+// serverSideApplyAction 返回以服务端 apply 方式处理清单对象的操作
+func serverSideApplyAction(opts ApplyOptions) manifestAction {
+	return func(resource dynamic.ResourceInterface, obj *unstructured.Unstructured) (string, error) {
+		if err := serverSideApply(resource, obj, opts); err != nil {
+			return "", err
+		}
+		return "serverside-applied", nil
+	}
+}
+
+// toApplyConflictError 从 API 返回的 409 错误中提取字段冲突，非字段冲突时返回 nil
+func toApplyConflictError(obj *unstructured.Unstructured, err error) *ApplyConflictError {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || !apierrors.IsConflict(err) || statusErr.ErrStatus.Details == nil {
+		return nil
+	}
+
+	var conflicts []FieldConflict
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflicts = append(conflicts, FieldConflict{
+			Field:   cause.Field,
+			Manager: conflictManager(cause.Message),
+			Message: cause.Message,
+		})
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return &ApplyConflictError{
+		Kind:      obj.GetKind(),
+		Name:      obj.GetName(),
+		Message:   statusErr.ErrStatus.Message,
+		Conflicts: conflicts,
+		err:       err,
+	}
+}
+
+// conflictManager 从形如 `conflict with "kubectl" using apps/v1` 的消息中提取管理者名称
+func conflictManager(message string) string {
+	start := strings.Index(message, `"`)
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(message[start+1:], `"`)
+	if end < 0 {
+		return ""
+	}
+	return message[start+1 : start+1+end]
+}
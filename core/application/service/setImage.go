@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SetImageOptions 修改镜像的参数
+type SetImageOptions struct {
+	// Images 容器名称（包括 init 容器）到新镜像的映射
+	Images map[string]string
+	// ChangeCause 写入 kubernetes.io/change-cause 注解的变更原因，为空时根据修改的镜像生成
+	ChangeCause string
+}
+
+// SetImageResult 修改镜像的结果
+type SetImageResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Images 被修改的容器的新镜像
+	Images map[string]string `json:"images"`
+	// PreviousImages 被修改的容器原来的镜像，可以原样作为 Images 提交用于回退
+	PreviousImages map[string]string `json:"previousImages"`
+	ChangeCause    string            `json:"changeCause,omitempty"`
+	// Skipped 所有容器已经在使用目标镜像，没有执行修改
+	Skipped bool   `json:"skipped"`
+	Message string `json:"message"`
+}
+
+// SetImage 修改 Deployment、StatefulSet 或 DaemonSet 中指定容器的镜像，不修改其他配置。
+// 所有容器都必须存在，修改时以读取到的 resourceVersion 为前置条件，保证返回的原镜像准确
+func (s *ClusterService) SetImage(clusterID int, namespace, kind, name string, opts SetImageOptions) (*SetImageResult, error) {
+	if len(opts.Images) == 0 {
+		return nil, fmt.Errorf("%w: at least one container image is required", ErrInvalidRequest)
+	}
+	for container, image := range opts.Images {
+		if strings.TrimSpace(image) == "" {
+			return nil, fmt.Errorf("%w: image of container %q is empty", ErrInvalidRequest, container)
+		}
+	}
+
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+	return setImage(clients.Clientset, namespace, kind, name, opts)
+}
+
+// setImage 校验容器名称后以 strategic merge patch 修改镜像，已经是目标镜像的容器不修改
+func setImage(clientset kubernetes.Interface, namespace, kind, name string, opts SetImageOptions) (*SetImageResult, error) {
+	meta, template, err := workloadTemplate(clientset, namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]string, len(template.Spec.InitContainers)+len(template.Spec.Containers))
+	var names []string
+	for _, containers := range [][]corev1.Container{template.Spec.InitContainers, template.Spec.Containers} {
+		for _, container := range containers {
+			current[container.Name] = container.Image
+			names = append(names, container.Name)
+		}
+	}
+	var unknown []string
+	for container := range opts.Images {
+		if _, ok := current[container]; !ok {
+			unknown = append(unknown, container)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: containers %s not found in %s %s, available containers: %s",
+			ErrInvalidRequest, strings.Join(unknown, ", "), kind, name, strings.Join(names, ", "))
+	}
+
+	result := &SetImageResult{
+		Kind:           kind,
+		Namespace:      namespace,
+		Name:           name,
+		Images:         make(map[string]string, len(opts.Images)),
+		PreviousImages: make(map[string]string, len(opts.Images)),
+	}
+	for container, image := range opts.Images {
+		result.Images[container] = image
+		result.PreviousImages[container] = current[container]
+	}
+
+	var containers, initContainers []map[string]string
+	for _, container := range template.Spec.Containers {
+		if image, ok := opts.Images[container.Name]; ok && image != container.Image {
+			containers = append(containers, map[string]string{"name": container.Name, "image": image})
+		}
+	}
+	for _, container := range template.Spec.InitContainers {
+		if image, ok := opts.Images[container.Name]; ok && image != container.Image {
+			initContainers = append(initContainers, map[string]string{"name": container.Name, "image": image})
+		}
+	}
+	if len(containers) == 0 && len(initContainers) == 0 {
+		result.Skipped = true
+		result.Message = fmt.Sprintf("%s %q already uses the requested images", kind, name)
+		return result, nil
+	}
+
+	result.ChangeCause = opts.ChangeCause
+	if result.ChangeCause == "" {
+		result.ChangeCause = "set image " + formatImages(opts.Images)
+	}
+
+	// strategic merge patch 按名称合并容器，与 kubectl set image 一致
+	podSpec := map[string]interface{}{}
+	if len(containers) > 0 {
+		podSpec["containers"] = containers
+	}
+	if len(initContainers) > 0 {
+		podSpec["initContainers"] = initContainers
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": meta.ResourceVersion,
+			"annotations":     map[string]string{changeCauseAnnotation: result.ChangeCause},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{"spec": podSpec},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %v", err)
+	}
+	if err := patchWorkload(clientset, namespace, kind, name, patch); err != nil {
+		return nil, err
+	}
+
+	result.Message = fmt.Sprintf("%s %q image updated", kind, name)
+	return result, nil
+}
+
+// workloadTemplate 读取工作负载的元数据和 Pod 模板
+func workloadTemplate(clientset kubernetes.Interface, namespace, kind, name string) (*metav1.ObjectMeta, *corev1.PodTemplateSpec, error) {
+	switch kind {
+	case "Deployment":
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		return &deployment.ObjectMeta, &deployment.Spec.Template, nil
+	case "StatefulSet":
+		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get statefulSet: %w", err)
+		}
+		return &statefulSet.ObjectMeta, &statefulSet.Spec.Template, nil
+	case "DaemonSet":
+		daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get daemonSet: %w", err)
+		}
+		return &daemonSet.ObjectMeta, &daemonSet.Spec.Template, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: setting images is not supported for %s", ErrInvalidRequest, kind)
+	}
+}
+
+// formatImages 按容器名称排序，格式为 name=image，多个之间以逗号分隔
+func formatImages(images map[string]string) string {
+	pairs := make([]string, 0, len(images))
+	for container, image := range images {
+		pairs = append(pairs, container+"="+image)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
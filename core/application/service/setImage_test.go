@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSetImage(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		opts SetImageOptions
+		// wantImages 修改后各容器（包括 init 容器）的镜像
+		wantImages      map[string]string
+		wantPrevious    map[string]string
+		wantChangeCause string
+		wantSkipped     bool
+		wantErr         error
+	}{
+		{
+			name:            "container",
+			kind:            "Deployment",
+			opts:            SetImageOptions{Images: map[string]string{"web": "web:v2"}},
+			wantImages:      map[string]string{"migrate": "migrate:v1", "web": "web:v2", "proxy": "proxy:v1"},
+			wantPrevious:    map[string]string{"web": "web:v1"},
+			wantChangeCause: "set image web=web:v2",
+		},
+		{
+			// init 容器按名称匹配，其他容器保持不变
+			name:            "init container with change cause",
+			kind:            "StatefulSet",
+			opts:            SetImageOptions{Images: map[string]string{"migrate": "migrate:v2", "proxy": "proxy:v2"}, ChangeCause: "release 2"},
+			wantImages:      map[string]string{"migrate": "migrate:v2", "web": "web:v1", "proxy": "proxy:v2"},
+			wantPrevious:    map[string]string{"migrate": "migrate:v1", "proxy": "proxy:v1"},
+			wantChangeCause: "release 2",
+		},
+		{
+			name:         "already up to date",
+			kind:         "DaemonSet",
+			opts:         SetImageOptions{Images: map[string]string{"web": "web:v1"}},
+			wantImages:   map[string]string{"migrate": "migrate:v1", "web": "web:v1", "proxy": "proxy:v1"},
+			wantPrevious: map[string]string{"web": "web:v1"},
+			wantSkipped:  true,
+		},
+		{
+			name:    "unknown container",
+			kind:    "Deployment",
+			opts:    SetImageOptions{Images: map[string]string{"web": "web:v2", "worker": "worker:v2"}},
+			wantErr: ErrInvalidRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "migrate", Image: "migrate:v1"}},
+				Containers:     []corev1.Container{{Name: "web", Image: "web:v1"}, {Name: "proxy", Image: "proxy:v1"}},
+			}}
+			meta := metav1.ObjectMeta{Name: "web", Namespace: "prod", ResourceVersion: "1"}
+			clientset := fake.NewSimpleClientset(
+				&appsv1.Deployment{ObjectMeta: meta, Spec: appsv1.DeploymentSpec{Template: template}},
+				&appsv1.StatefulSet{ObjectMeta: meta, Spec: appsv1.StatefulSetSpec{Template: template}},
+				&appsv1.DaemonSet{ObjectMeta: meta, Spec: appsv1.DaemonSetSpec{Template: template}},
+			)
+
+			result, err := setImage(clientset, "prod", tt.kind, "web", tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Skipped != tt.wantSkipped || result.ChangeCause != tt.wantChangeCause {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+			if !reflect.DeepEqual(result.PreviousImages, tt.wantPrevious) {
+				t.Fatalf("expected previous images %v, got %v", tt.wantPrevious, result.PreviousImages)
+			}
+
+			updatedMeta, updated, err := workloadTemplate(clientset, "prod", tt.kind, "web")
+			if err != nil {
+				t.Fatal(err)
+			}
+			images := map[string]string{}
+			for _, container := range append(updated.Spec.InitContainers, updated.Spec.Containers...) {
+				images[container.Name] = container.Image
+			}
+			if !reflect.DeepEqual(images, tt.wantImages) {
+				t.Fatalf("expected images %v, got %v", tt.wantImages, images)
+			}
+			if got := updatedMeta.Annotations[changeCauseAnnotation]; got != tt.wantChangeCause {
+				t.Fatalf("expected change cause %q, got %q", tt.wantChangeCause, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package service
+
+import "time"
+
+const (
+	// defaultWaitTimeout 等待资源就绪的默认超时时间
+	defaultWaitTimeout = 5 * time.Minute
+	// waitPollInterval 等待时轮询资源状态的间隔
+	waitPollInterval = 2 * time.Second
+)
+
+// waitDeadline 计算等待的截止时间，同一请求中等待多个对象时共用
+func waitDeadline(timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		timeout = defaultWaitTimeout
+	}
+	return time.Now().Add(timeout)
+}
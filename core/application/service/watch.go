@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// WatchOptions 监听资源变化的参数
+type WatchOptions struct {
+	LabelSelector string
+	FieldSelector string
+	// ResourceVersion 从该版本之后开始监听，用于断线后恢复；为空时先以 ADDED 事件返回当前的全部对象
+	ResourceVersion string
+}
+
+// WatchEvent 资源变化事件，Type 为 ADDED、MODIFIED、DELETED、BOOKMARK 或 ERROR
+type WatchEvent struct {
+	Type string `json:"type"`
+	// ResourceVersion 事件对应的资源版本，断线后从该版本恢复监听
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// Object 变化后的对象，ERROR 时为 API Server 返回的 Status，BOOKMARK 时为空
+	Object map[string]interface{} `json:"object,omitempty"`
+}
+
+// ResourceWatch 资源变化事件流，API Server 关闭连接时自动从最后的版本重新监听
+type ResourceWatch struct {
+	events chan WatchEvent
+	cancel context.CancelFunc
+}
+
+// Events 返回事件，监听停止后关闭。资源版本过期（410 Gone）时先返回 ERROR 事件再关闭，客户端需要不带版本重新监听
+func (w *ResourceWatch) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Stop 停止监听
+func (w *ResourceWatch) Stop() {
+	w.cancel()
+	for range w.events {
+	}
+}
+
+// WatchResource 监听资源的变化，resource 为资源的复数名称，CRD 使用 resource.group 形式。
+// namespace 为空时监听所有命名空间，集群级资源忽略 namespace
+func (s *ClusterService) WatchResource(ctx context.Context, clusterID int, namespace, resource string, opts WatchOptions) (*ResourceWatch, error) {
+	if _, err := labels.Parse(opts.LabelSelector); err != nil {
+		return nil, fmt.Errorf("%w: invalid labelSelector: %v", ErrInvalidRequest, err)
+	}
+	if _, err := fields.ParseSelector(opts.FieldSelector); err != nil {
+		return nil, fmt.Errorf("%w: invalid fieldSelector: %v", ErrInvalidRequest, err)
+	}
+
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := resolveResource(clients, resource)
+	if err != nil {
+		return nil, err
+	}
+	var resourceClient dynamic.ResourceInterface = clients.Dynamic.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && namespace != "" {
+		resourceClient = clients.Dynamic.Resource(mapping.Resource).Namespace(namespace)
+	}
+	return watchResource(ctx, resourceClient, resource, opts)
+}
+
+// watchResource 未指定版本时先返回当前对象，再通过 RetryWatcher 持续监听
+func watchResource(ctx context.Context, resourceClient dynamic.ResourceInterface, resource string, opts WatchOptions) (*ResourceWatch, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	resourceVersion := opts.ResourceVersion
+	var initial []unstructured.Unstructured
+	if resourceVersion == "" || resourceVersion == "0" {
+		// 没有指定版本时先列出当前对象，再从列表的版本开始监听，保证不漏掉中间的变化
+		list, err := resourceClient.List(ctx, metav1.ListOptions{
+			LabelSelector: opts.LabelSelector,
+			FieldSelector: opts.FieldSelector,
+		})
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to list %s: %w", resource, err)
+		}
+		resourceVersion = list.GetResourceVersion()
+		initial = list.Items
+	}
+
+	watcher, err := watchtools.NewRetryWatcher(resourceVersion, &cache.ListWatch{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = opts.LabelSelector
+			options.FieldSelector = opts.FieldSelector
+			options.AllowWatchBookmarks = true
+			return resourceClient.Watch(ctx, options)
+		},
+	})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to watch %s: %w", resource, err)
+	}
+
+	resourceWatch := &ResourceWatch{events: make(chan WatchEvent), cancel: cancel}
+	go func() {
+		defer close(resourceWatch.events)
+		defer watcher.Stop()
+
+		send := func(event WatchEvent) bool {
+			select {
+			case resourceWatch.events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for i := range initial {
+			if !send(watchEvent(watch.Added, &initial[i])) {
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+				if !send(watchEvent(event.Type, event.Object)) {
+					return
+				}
+			}
+		}
+	}()
+	return resourceWatch, nil
+}
+
+func watchEvent(eventType watch.EventType, obj runtime.Object) WatchEvent {
+	event := WatchEvent{Type: string(eventType)}
+	switch o := obj.(type) {
+	case *unstructured.Unstructured:
+		event.ResourceVersion = o.GetResourceVersion()
+		if eventType == watch.Bookmark {
+			return event
+		}
+		// managedFields 对前端没有意义且体积较大
+		o.SetManagedFields(nil)
+		event.Object = o.Object
+	case *metav1.Status:
+		event.Object, _ = runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+	}
+	return event
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWatchEvent(t *testing.T) {
+	withManagedFields := func() *unstructured.Unstructured {
+		obj := testConfigMap("v")
+		obj.SetResourceVersion("7")
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl"}})
+		return obj
+	}
+	gone := apierrors.NewResourceExpired("too old resource version")
+
+	tests := []struct {
+		name      string
+		eventType watch.EventType
+		obj       runtime.Object
+		want      WatchEvent
+	}{
+		{
+			// managedFields 不返回给前端
+			name:      "modified",
+			eventType: watch.Modified,
+			obj:       withManagedFields(),
+			want:      WatchEvent{Type: "MODIFIED", ResourceVersion: "7", Object: testConfigMapWithVersion("7").Object},
+		},
+		{
+			name:      "bookmark without object",
+			eventType: watch.Bookmark,
+			obj:       withManagedFields(),
+			want:      WatchEvent{Type: "BOOKMARK", ResourceVersion: "7"},
+		},
+		{
+			name:      "error status",
+			eventType: watch.Error,
+			obj:       &gone.ErrStatus,
+			want: WatchEvent{Type: "ERROR", Object: map[string]interface{}{
+				"metadata": map[string]interface{}{},
+				"status":   metav1.StatusFailure,
+				"message":  "too old resource version",
+				"reason":   string(metav1.StatusReasonExpired),
+				"code":     int64(http.StatusGone),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := watchEvent(tt.eventType, tt.obj); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func testConfigMapWithVersion(resourceVersion string) *unstructured.Unstructured {
+	obj := testConfigMap("v")
+	obj.SetResourceVersion(resourceVersion)
+	return obj
+}
+
+func TestWatchResource(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), testConfigMapWithVersion("1"))
+	// fake 客户端返回的列表没有 resourceVersion，RetryWatcher 需要从列表的版本开始监听
+	client.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*testConfigMapWithVersion("1")}}
+		list.SetResourceVersion("1")
+		return true, list, nil
+	})
+	// 监听建立后才修改对象，否则 fake 客户端不会补发之前的变化
+	watching := make(chan struct{})
+	var once sync.Once
+	client.PrependWatchReactor("configmaps", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watcher, err := client.Tracker().Watch(configMapsResource, action.GetNamespace())
+		once.Do(func() { close(watching) })
+		return true, watcher, err
+	})
+	resource := client.Resource(configMapsResource).Namespace("default")
+
+	resourceWatch, err := watchResource(context.Background(), resource, "configmaps", WatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resourceWatch.Stop()
+
+	next := func() WatchEvent {
+		select {
+		case event, ok := <-resourceWatch.Events():
+			if !ok {
+				t.Fatal("watch closed")
+			}
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+		}
+		return WatchEvent{}
+	}
+
+	// 先以 ADDED 返回当前对象
+	if event := next(); event.Type != "ADDED" || event.ResourceVersion != "1" {
+		t.Fatalf("unexpected initial event %+v", event)
+	}
+
+	select {
+	case <-watching:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch was never started")
+	}
+	updated := testConfigMap("new")
+	updated.SetResourceVersion("2")
+	if _, err := resource.Update(context.Background(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	event := next()
+	if event.Type != "MODIFIED" || event.ResourceVersion != "2" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if value, _, _ := unstructured.NestedString(event.Object, "data", "key"); value != "new" {
+		t.Fatalf("expected data.key new, got %q", value)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// EventOptions 事件查询参数
+type EventOptions struct {
+	// Type 只返回指定类型的事件：Warning 或 Normal，为空时返回全部
+	Type string
+}
+
+// EventSummary 按类型和原因合并后的事件
+type EventSummary struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+	// Message 最近一次事件的内容
+	Message string `json:"message"`
+	// Count 合并的事件发生的总次数
+	Count          int32     `json:"count"`
+	FirstTimestamp time.Time `json:"firstTimestamp"`
+	LastTimestamp  time.Time `json:"lastTimestamp"`
+	// Objects 产生事件的对象，如 Pod/web-0，按最近发生的顺序排列
+	Objects []string `json:"objects"`
+}
+
+// ListWorkloadEvents 汇总 Deployment 或 StatefulSet、其 ReplicaSet 或 ControllerRevision 以及 Pod 的事件，
+// 按类型和原因去重，按最近发生时间倒序排列
+func (s *ClusterService) ListWorkloadEvents(clusterID int, namespace, kind, name string, opts EventOptions) ([]EventSummary, error) {
+	if opts.Type != "" && opts.Type != corev1.EventTypeWarning && opts.Type != corev1.EventTypeNormal {
+		return nil, fmt.Errorf("%w: invalid event type %q, must be Warning or Normal", ErrInvalidRequest, opts.Type)
+	}
+
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+	return workloadEvents(context.Background(), s.reader(clients), namespace, kind, name, opts.Type)
+}
+
+// relatedObjects 与工作负载相关的对象，已删除的 Pod 没有 UID，按名称前缀匹配
+type relatedObjects struct {
+	uids map[types.UID]bool
+	// podPrefixes Deployment 为各 ReplicaSet 的名称加 -，StatefulSet 为自身名称加 -
+	podPrefixes []string
+	// ordinalPods StatefulSet 的 Pod 名称后缀为序号
+	ordinalPods bool
+}
+
+func (o *relatedObjects) matches(object corev1.ObjectReference) bool {
+	if object.UID != "" && o.uids[object.UID] {
+		return true
+	}
+	if object.Kind != "Pod" {
+		return false
+	}
+	for _, prefix := range o.podPrefixes {
+		suffix, ok := strings.CutPrefix(object.Name, prefix)
+		if !ok {
+			continue
+		}
+		if !o.ordinalPods {
+			return true
+		}
+		if _, err := strconv.Atoi(suffix); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func workloadEvents(ctx context.Context, reader clusterReader, namespace, kind, name, eventType string) ([]EventSummary, error) {
+	related, err := workloadObjects(ctx, reader, namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := reader.listEvents(ctx, namespace, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	var matched []corev1.Event
+	for _, event := range events {
+		if related.matches(event.InvolvedObject) {
+			matched = append(matched, event)
+		}
+	}
+	return summarizeEvents(matched), nil
+}
+
+// workloadObjects 收集工作负载、其 ReplicaSet 或 ControllerRevision 以及当前 Pod 的 UID
+func workloadObjects(ctx context.Context, reader clusterReader, namespace, kind, name string) (*relatedObjects, error) {
+	related := &relatedObjects{uids: map[types.UID]bool{}}
+	var pods []controlledPod
+	switch kind {
+	case "Deployment":
+		deployment, err := reader.getDeployment(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		related.uids[deployment.UID] = true
+
+		replicaSets, err := ownedReplicaSets(ctx, reader, deployment)
+		if err != nil {
+			return nil, err
+		}
+		for _, replicaSet := range replicaSets {
+			related.uids[replicaSet.UID] = true
+			related.podPrefixes = append(related.podPrefixes, replicaSet.Name+"-")
+		}
+
+		if pods, err = deploymentPods(ctx, reader, deployment); err != nil {
+			return nil, err
+		}
+	case "StatefulSet":
+		statefulSet, err := reader.getStatefulSet(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get statefulSet: %w", err)
+		}
+		related.uids[statefulSet.UID] = true
+		related.podPrefixes = []string{statefulSet.Name + "-"}
+		related.ordinalPods = true
+
+		revisions, err := ownedControllerRevisions(ctx, reader, statefulSet)
+		if err != nil {
+			return nil, err
+		}
+		for _, revision := range revisions {
+			related.uids[revision.UID] = true
+		}
+
+		if pods, err = statefulSetPods(ctx, reader, statefulSet); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: listing events is not supported for %s", ErrInvalidRequest, kind)
+	}
+
+	for _, pod := range pods {
+		related.uids[pod.UID] = true
+	}
+	return related, nil
+}
+
+// summarizeEvents 按类型和原因合并事件，按最近发生时间倒序排列
+func summarizeEvents(events []corev1.Event) []EventSummary {
+	sort.Slice(events, func(i, j int) bool {
+		return eventLastTime(events[i]).After(eventLastTime(events[j]))
+	})
+
+	summaries := []EventSummary{}
+	index := map[string]int{}
+	for _, event := range events {
+		object := event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name
+		key := event.Type + "/" + event.Reason
+		i, ok := index[key]
+		if !ok {
+			// 事件已按时间倒序排列，第一次出现的就是最近的一次
+			index[key] = len(summaries)
+			summaries = append(summaries, EventSummary{
+				Type:           event.Type,
+				Reason:         event.Reason,
+				Message:        event.Message,
+				Count:          eventCount(event),
+				FirstTimestamp: eventFirstTime(event),
+				LastTimestamp:  eventLastTime(event),
+				Objects:        []string{object},
+			})
+			continue
+		}
+
+		summary := &summaries[i]
+		summary.Count += eventCount(event)
+		if first := eventFirstTime(event); first.Before(summary.FirstTimestamp) {
+			summary.FirstTimestamp = first
+		}
+		if !containsString(summary.Objects, object) {
+			summary.Objects = append(summary.Objects, object)
+		}
+	}
+	return summaries
+}
+
+// eventLastTime 兼容 core/v1 事件和 events.k8s.io 写入的事件，后者只有 eventTime 和 series
+func eventLastTime(event corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+func eventFirstTime(event corev1.Event) time.Time {
+	switch {
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+func eventCount(event corev1.Event) int32 {
+	if event.Series != nil && event.Series.Count > 0 {
+		return event.Series.Count
+	}
+	if event.Count > 0 {
+		return event.Count
+	}
+	return 1
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRelatedObjectsMatches(t *testing.T) {
+	deployment := &relatedObjects{
+		uids:        map[types.UID]bool{"web-uid": true},
+		podPrefixes: []string{"web-7d9f-"},
+	}
+	statefulSet := &relatedObjects{
+		uids:        map[types.UID]bool{"db-uid": true},
+		podPrefixes: []string{"db-"},
+		ordinalPods: true,
+	}
+	tests := []struct {
+		name    string
+		related *relatedObjects
+		object  corev1.ObjectReference
+		want    bool
+	}{
+		{"by uid", deployment, corev1.ObjectReference{Kind: "Deployment", Name: "web", UID: "web-uid"}, true},
+		{"other uid", deployment, corev1.ObjectReference{Kind: "Deployment", Name: "web", UID: "other"}, false},
+		// 已删除的 Pod 按 ReplicaSet 名称前缀匹配
+		{"deleted pod by prefix", deployment, corev1.ObjectReference{Kind: "Pod", Name: "web-7d9f-x2k4p", UID: "gone"}, true},
+		{"pod of other replicaset", deployment, corev1.ObjectReference{Kind: "Pod", Name: "web-8c1a-x2k4p"}, false},
+		{"prefix only applies to pods", deployment, corev1.ObjectReference{Kind: "ReplicaSet", Name: "web-7d9f-x"}, false},
+		{"ordinal pod", statefulSet, corev1.ObjectReference{Kind: "Pod", Name: "db-0"}, true},
+		// db-backup-0 属于另一个名为 db-backup 的 StatefulSet
+		{"pod of other statefulset", statefulSet, corev1.ObjectReference{Kind: "Pod", Name: "db-backup-0"}, false},
+		{"statefulset uid", statefulSet, corev1.ObjectReference{Kind: "StatefulSet", Name: "db", UID: "db-uid"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.related.matches(tt.object); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSummarizeEvents(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) metav1.Time {
+		return metav1.NewTime(base.Add(time.Duration(minutes) * time.Minute))
+	}
+	event := func(eventType, reason, pod, message string, first, last int, count int32) corev1.Event {
+		return corev1.Event{
+			Type:           eventType,
+			Reason:         reason,
+			Message:        message,
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod},
+			FirstTimestamp: at(first),
+			LastTimestamp:  at(last),
+			Count:          count,
+		}
+	}
+	// events.k8s.io 写入的事件只有 eventTime 和 series
+	series := corev1.Event{
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-2"},
+		EventTime:      metav1.NewMicroTime(base.Add(2 * time.Minute)),
+		Series:         &corev1.EventSeries{Count: 4, LastObservedTime: metav1.NewMicroTime(base.Add(30 * time.Minute))},
+	}
+
+	got := summarizeEvents([]corev1.Event{
+		event(corev1.EventTypeNormal, "Pulled", "web-1", "pulled web:v1", 1, 1, 0),
+		event(corev1.EventTypeWarning, "BackOff", "web-1", "old back-off", 5, 20, 3),
+		series,
+		event(corev1.EventTypeWarning, "BackOff", "web-1", "older back-off", 3, 10, 1),
+	})
+	want := []EventSummary{
+		{
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Count:          8,
+			FirstTimestamp: base.Add(2 * time.Minute),
+			LastTimestamp:  base.Add(30 * time.Minute),
+			Objects:        []string{"Pod/web-2", "Pod/web-1"},
+		},
+		{
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Pulled",
+			Message:        "pulled web:v1",
+			Count:          1,
+			FirstTimestamp: base.Add(time.Minute),
+			LastTimestamp:  base.Add(time.Minute),
+			Objects:        []string{"Pod/web-1"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	if got := summarizeEvents(nil); got == nil || len(got) != 0 {
+		t.Fatalf("expected an empty list, got %#v", got)
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	// defaultListLimit 未指定 limit 时每页返回的数量，与 kubectl 的分页大小一致
+	defaultListLimit = 500
+	// maxListLimit 单页允许的最大数量
+	maxListLimit = 1000
+)
+
+// ListOptions 列表查询参数
+type ListOptions struct {
+	LabelSelector string
+	FieldSelector string
+	// Limit 每页数量，为 0 时使用 defaultListLimit
+	Limit int64
+	// Continue 上一页返回的分页令牌
+	Continue string
+}
+
+// WorkloadCondition 工作负载的状态条件
+type WorkloadCondition struct {
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	Reason             string     `json:"reason,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	LastTransitionTime *time.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// WorkloadSummary Deployment 或 StatefulSet 的摘要信息
+type WorkloadSummary struct {
+	Kind              string              `json:"kind"`
+	Namespace         string              `json:"namespace"`
+	Name              string              `json:"name"`
+	Replicas          int32               `json:"replicas"`
+	ReadyReplicas     int32               `json:"readyReplicas"`
+	UpdatedReplicas   int32               `json:"updatedReplicas"`
+	AvailableReplicas int32               `json:"availableReplicas"`
+	Images            []string            `json:"images"`
+	CreatedAt         time.Time           `json:"createdAt"`
+	Age               string              `json:"age"`
+	Conditions        []WorkloadCondition `json:"conditions,omitempty"`
+}
+
+// WorkloadList 一页工作负载摘要，Continue 不为空时表示还有下一页
+type WorkloadList struct {
+	Items              []WorkloadSummary `json:"items"`
+	Continue           string            `json:"continue,omitempty"`
+	RemainingItemCount *int64            `json:"remainingItemCount,omitempty"`
+}
+
+// ListDeployments 列出指定集群的 Deployment，namespace 为空时列出所有命名空间
+func (s *ClusterService) ListDeployments(clusterID int, namespace string, opts ListOptions) (*WorkloadList, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := s.reader(clients).listDeployments(context.Background(), namespace, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	now := time.Now()
+	list := &WorkloadList{
+		Items:              make([]WorkloadSummary, 0, len(deployments.Items)),
+		Continue:           deployments.Continue,
+		RemainingItemCount: deployments.RemainingItemCount,
+	}
+	for _, deployment := range deployments.Items {
+		summary := workloadSummary("Deployment", deployment.ObjectMeta, deployment.Spec.Replicas, deployment.Spec.Template.Spec, now)
+		summary.ReadyReplicas = deployment.Status.ReadyReplicas
+		summary.UpdatedReplicas = deployment.Status.UpdatedReplicas
+		summary.AvailableReplicas = deployment.Status.AvailableReplicas
+		for _, condition := range deployment.Status.Conditions {
+			summary.Conditions = append(summary.Conditions, deploymentCondition(condition))
+		}
+		list.Items = append(list.Items, summary)
+	}
+	return list, nil
+}
+
+// ListStatefulSets 列出指定集群的 StatefulSet，namespace 为空时列出所有命名空间
+func (s *ClusterService) ListStatefulSets(clusterID int, namespace string, opts ListOptions) (*WorkloadList, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	statefulSets, err := s.reader(clients).listStatefulSets(context.Background(), namespace, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulSets: %w", err)
+	}
+
+	now := time.Now()
+	list := &WorkloadList{
+		Items:              make([]WorkloadSummary, 0, len(statefulSets.Items)),
+		Continue:           statefulSets.Continue,
+		RemainingItemCount: statefulSets.RemainingItemCount,
+	}
+	for _, statefulSet := range statefulSets.Items {
+		summary := workloadSummary("StatefulSet", statefulSet.ObjectMeta, statefulSet.Spec.Replicas, statefulSet.Spec.Template.Spec, now)
+		summary.ReadyReplicas = statefulSet.Status.ReadyReplicas
+		summary.UpdatedReplicas = statefulSet.Status.UpdatedReplicas
+		summary.AvailableReplicas = statefulSet.Status.AvailableReplicas
+		for _, condition := range statefulSet.Status.Conditions {
+			summary.Conditions = append(summary.Conditions, statefulSetCondition(condition))
+		}
+		list.Items = append(list.Items, summary)
+	}
+	return list, nil
+}
+
+func (o ListOptions) toListOptions() metav1.ListOptions {
+	limit := o.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return metav1.ListOptions{
+		LabelSelector: o.LabelSelector,
+		FieldSelector: o.FieldSelector,
+		Limit:         limit,
+		Continue:      o.Continue,
+	}
+}
+
+// workloadSummary 填充 Deployment 和 StatefulSet 共有的字段，未设置副本数时按默认值 1 处理
+func workloadSummary(kind string, meta metav1.ObjectMeta, replicas *int32, podSpec corev1.PodSpec, now time.Time) WorkloadSummary {
+	summary := WorkloadSummary{
+		Kind:      kind,
+		Namespace: meta.Namespace,
+		Name:      meta.Name,
+		Replicas:  1,
+		Images:    make([]string, 0, len(podSpec.Containers)),
+		CreatedAt: meta.CreationTimestamp.Time,
+		Age:       duration.HumanDuration(now.Sub(meta.CreationTimestamp.Time)),
+	}
+	if replicas != nil {
+		summary.Replicas = *replicas
+	}
+	for _, container := range podSpec.Containers {
+		summary.Images = append(summary.Images, container.Image)
+	}
+	return summary
+}
+
+func deploymentCondition(condition appsv1.DeploymentCondition) WorkloadCondition {
+	return WorkloadCondition{
+		Type:               string(condition.Type),
+		Status:             string(condition.Status),
+		Reason:             condition.Reason,
+		Message:            condition.Message,
+		LastTransitionTime: optionalTime(condition.LastTransitionTime),
+	}
+}
+
+func statefulSetCondition(condition appsv1.StatefulSetCondition) WorkloadCondition {
+	return WorkloadCondition{
+		Type:               string(condition.Type),
+		Status:             string(condition.Status),
+		Reason:             condition.Reason,
+		Message:            condition.Message,
+		LastTransitionTime: optionalTime(condition.LastTransitionTime),
+	}
+}
+
+// optionalTime 未设置的时间返回 nil，序列化时省略
+func optionalTime(t metav1.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t.Time
+}
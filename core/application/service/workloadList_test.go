@@ -0,0 +1,81 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestToListOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ListOptions
+		want metav1.ListOptions
+	}{
+		{name: "default limit", opts: ListOptions{}, want: metav1.ListOptions{Limit: defaultListLimit}},
+		{name: "negative limit", opts: ListOptions{Limit: -1}, want: metav1.ListOptions{Limit: defaultListLimit}},
+		{name: "limit capped", opts: ListOptions{Limit: maxListLimit + 1}, want: metav1.ListOptions{Limit: maxListLimit}},
+		{
+			name: "selectors and continue",
+			opts: ListOptions{LabelSelector: "app=web", FieldSelector: "metadata.name=web", Limit: 20, Continue: "token"},
+			want: metav1.ListOptions{LabelSelector: "app=web", FieldSelector: "metadata.name=web", Limit: 20, Continue: "token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.toListOptions(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWorkloadSummary(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	meta := metav1.ObjectMeta{Name: "web", Namespace: "prod", CreationTimestamp: metav1.NewTime(created)}
+	podSpec := corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "migrate", Image: "migrate:v1"}},
+		Containers:     []corev1.Container{{Name: "web", Image: "web:v1"}, {Name: "proxy", Image: "proxy:v1"}},
+	}
+	tests := []struct {
+		name     string
+		replicas *int32
+		want     int32
+	}{
+		// 未设置副本数时按默认值 1 处理
+		{name: "default replicas", want: 1},
+		{name: "scaled to zero", replicas: int32Ptr(0), want: 0},
+		{name: "replicas", replicas: int32Ptr(3), want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := workloadSummary("Deployment", meta, tt.replicas, podSpec, created.Add(90*time.Minute))
+			if got.Replicas != tt.want {
+				t.Fatalf("expected %d replicas, got %d", tt.want, got.Replicas)
+			}
+			if !reflect.DeepEqual(got.Images, []string{"web:v1", "proxy:v1"}) {
+				t.Fatalf("unexpected images %v", got.Images)
+			}
+			if got.Age != "90m" || !got.CreatedAt.Equal(created) {
+				t.Fatalf("unexpected age %s, createdAt %s", got.Age, got.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestWorkloadConditions(t *testing.T) {
+	transition := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	got := deploymentCondition(appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, LastTransitionTime: transition})
+	if got.LastTransitionTime == nil || !got.LastTransitionTime.Equal(transition.Time) {
+		t.Fatalf("expected lastTransitionTime %s, got %v", transition, got.LastTransitionTime)
+	}
+	// 未设置的时间序列化时省略
+	got = statefulSetCondition(appsv1.StatefulSetCondition{Type: "Ready", Status: corev1.ConditionFalse, Reason: "PodsNotReady"})
+	if got.LastTransitionTime != nil || got.Type != "Ready" || got.Status != "False" || got.Reason != "PodsNotReady" {
+		t.Fatalf("unexpected condition %+v", got)
+	}
+}
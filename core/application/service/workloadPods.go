@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// PodSummary 工作负载下单个 Pod 的状态
+type PodSummary struct {
+	Name string `json:"name"`
+	// Phase Pod 的阶段，如 Pending、Running、Failed
+	Phase string `json:"phase"`
+	// Status 与 kubectl get pods 的 STATUS 列一致，如 CrashLoopBackOff、ImagePullBackOff、Terminating
+	Status string `json:"status"`
+	Ready  bool   `json:"ready"`
+	// ReadyContainers 就绪容器数与容器总数，如 1/2
+	ReadyContainers string `json:"readyContainers"`
+	Restarts        int32  `json:"restarts"`
+	// LastTerminationReason 最近一次重启的容器上次退出的原因，如 OOMKilled、Error
+	LastTerminationReason string   `json:"lastTerminationReason,omitempty"`
+	Node                  string   `json:"node,omitempty"`
+	PodIPs                []string `json:"podIPs,omitempty"`
+	HostIP                string   `json:"hostIP,omitempty"`
+	// Owner 直接控制 Pod 的 ReplicaSet 或 StatefulSet
+	Owner string `json:"owner,omitempty"`
+	// Revision Deployment 的版本号或 StatefulSet 的 controller-revision-hash
+	Revision string `json:"revision,omitempty"`
+	// Current Pod 是否属于当前版本，旧版本的 Pod 在发布过程中会被逐步替换
+	Current    bool               `json:"current"`
+	Containers []ContainerSummary `json:"containers"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	Age        string             `json:"age"`
+}
+
+// ContainerSummary 单个容器的状态
+type ContainerSummary struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	Init  bool   `json:"init,omitempty"`
+	Ready bool   `json:"ready"`
+	// State 容器当前状态：waiting、running 或 terminated
+	State        string `json:"state"`
+	Reason       string `json:"reason,omitempty"`
+	Message      string `json:"message,omitempty"`
+	RestartCount int32  `json:"restartCount"`
+	// LastTermination 容器上次退出的信息，容器重启过时才有
+	LastTermination *ContainerTermination `json:"lastTermination,omitempty"`
+}
+
+// ContainerTermination 容器退出的信息
+type ContainerTermination struct {
+	Reason     string     `json:"reason,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	ExitCode   int32      `json:"exitCode"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// controlledPod 工作负载控制的 Pod 及其所属的版本
+type controlledPod struct {
+	corev1.Pod
+	owner    string
+	revision string
+	current  bool
+}
+
+// ListWorkloadPods 解析 Deployment 或 StatefulSet 的 selector，列出其当前控制的 Pod
+func (s *ClusterService) ListWorkloadPods(clusterID int, namespace, kind, name string) ([]PodSummary, error) {
+	// 从客户端池获取集群客户端
+	clients, err := s.Clients.Get(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	pods, err := workloadPods(context.Background(), s.reader(clients), namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	summaries := make([]PodSummary, 0, len(pods))
+	for _, pod := range pods {
+		summary := podSummary(&pod.Pod, now)
+		summary.Owner = pod.owner
+		summary.Revision = pod.revision
+		summary.Current = pod.current
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// workloadPods 列出 Deployment 或 StatefulSet 当前控制的 Pod
+func workloadPods(ctx context.Context, reader clusterReader, namespace, kind, name string) ([]controlledPod, error) {
+	switch kind {
+	case "Deployment":
+		deployment, err := reader.getDeployment(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		return deploymentPods(ctx, reader, deployment)
+	case "StatefulSet":
+		statefulSet, err := reader.getStatefulSet(ctx, namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get statefulSet: %w", err)
+		}
+		return statefulSetPods(ctx, reader, statefulSet)
+	default:
+		return nil, fmt.Errorf("%w: listing pods is not supported for %s", ErrInvalidRequest, kind)
+	}
+}
+
+// deploymentPods Deployment 的 Pod 由其 ReplicaSet 控制，版本号取自 ReplicaSet
+func deploymentPods(ctx context.Context, reader clusterReader, deployment *appsv1.Deployment) ([]controlledPod, error) {
+	replicaSets, err := ownedReplicaSets(ctx, reader, deployment)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make(map[string]string, len(replicaSets))
+	for _, replicaSet := range replicaSets {
+		revisions[string(replicaSet.UID)] = replicaSet.Annotations[revisionAnnotation]
+	}
+
+	pods, err := selectPods(ctx, reader, deployment.Namespace, deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	current := deployment.Annotations[revisionAnnotation]
+	var controlled []controlledPod
+	for _, pod := range pods {
+		owner := metav1.GetControllerOf(&pod)
+		if owner == nil {
+			continue
+		}
+		revision, ok := revisions[string(owner.UID)]
+		if !ok {
+			continue
+		}
+		controlled = append(controlled, controlledPod{
+			Pod:      pod,
+			owner:    owner.Name,
+			revision: revision,
+			current:  revision == current,
+		})
+	}
+	return controlled, nil
+}
+
+// statefulSetPods StatefulSet 直接控制 Pod，版本取自 controller-revision-hash 标签
+func statefulSetPods(ctx context.Context, reader clusterReader, statefulSet *appsv1.StatefulSet) ([]controlledPod, error) {
+	pods, err := selectPods(ctx, reader, statefulSet.Namespace, statefulSet.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var controlled []controlledPod
+	for _, pod := range pods {
+		if !metav1.IsControlledBy(&pod, statefulSet) {
+			continue
+		}
+		revision := pod.Labels[appsv1.ControllerRevisionHashLabelKey]
+		controlled = append(controlled, controlledPod{
+			Pod:      pod,
+			owner:    statefulSet.Name,
+			revision: revision,
+			current:  revision == statefulSet.Status.UpdateRevision,
+		})
+	}
+	return controlled, nil
+}
+
+// selectPods 按工作负载的 selector 列出 Pod，按名称排序
+func selectPods(ctx context.Context, reader clusterReader, namespace string, labelSelector *metav1.LabelSelector) ([]corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %v", err)
+	}
+	pods, err := reader.listPods(ctx, namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+func podSummary(pod *corev1.Pod, now time.Time) PodSummary {
+	summary := PodSummary{
+		Name:      pod.Name,
+		Phase:     string(pod.Status.Phase),
+		Status:    podStatus(pod),
+		Node:      pod.Spec.NodeName,
+		HostIP:    pod.Status.HostIP,
+		CreatedAt: pod.CreationTimestamp.Time,
+		Age:       duration.HumanDuration(now.Sub(pod.CreationTimestamp.Time)),
+	}
+	for _, ip := range pod.Status.PodIPs {
+		summary.PodIPs = append(summary.PodIPs, ip.IP)
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			summary.Ready = condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	images := make(map[string]string, len(pod.Spec.Containers)+len(pod.Spec.InitContainers))
+	for _, container := range pod.Spec.InitContainers {
+		images[container.Name] = container.Image
+	}
+	for _, container := range pod.Spec.Containers {
+		images[container.Name] = container.Image
+	}
+
+	for _, status := range pod.Status.InitContainerStatuses {
+		container := containerSummary(status, images[status.Name])
+		container.Init = true
+		summary.Containers = append(summary.Containers, container)
+	}
+
+	var ready int
+	var lastRestart time.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		summary.Containers = append(summary.Containers, containerSummary(status, images[status.Name]))
+		summary.Restarts += status.RestartCount
+		if status.Ready {
+			ready++
+		}
+		// 取最近一次退出的容器的原因
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && !terminated.FinishedAt.Time.Before(lastRestart) {
+			lastRestart = terminated.FinishedAt.Time
+			summary.LastTerminationReason = terminated.Reason
+		}
+	}
+	summary.ReadyContainers = fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers))
+	return summary
+}
+
+func containerSummary(status corev1.ContainerStatus, image string) ContainerSummary {
+	container := ContainerSummary{
+		Name:         status.Name,
+		Image:        image,
+		Ready:        status.Ready,
+		RestartCount: status.RestartCount,
+	}
+	if image == "" {
+		container.Image = status.Image
+	}
+
+	switch {
+	case status.State.Waiting != nil:
+		container.State = "waiting"
+		container.Reason = status.State.Waiting.Reason
+		container.Message = status.State.Waiting.Message
+	case status.State.Terminated != nil:
+		container.State = "terminated"
+		container.Reason = status.State.Terminated.Reason
+		container.Message = status.State.Terminated.Message
+	case status.State.Running != nil:
+		container.State = "running"
+	}
+
+	if terminated := status.LastTerminationState.Terminated; terminated != nil {
+		container.LastTermination = &ContainerTermination{
+			Reason:   terminated.Reason,
+			Message:  terminated.Message,
+			ExitCode: terminated.ExitCode,
+		}
+		if !terminated.FinishedAt.IsZero() {
+			finishedAt := terminated.FinishedAt.Time
+			container.LastTermination.FinishedAt = &finishedAt
+		}
+	}
+	return container
+}
+
+// podStatus 与 kubectl get pods 的 STATUS 列逻辑一致
+func podStatus(pod *corev1.Pod) string {
+	reason := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+
+	initializing := false
+	for i, status := range pod.Status.InitContainerStatuses {
+		switch {
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode == 0:
+			continue
+		case status.State.Terminated != nil:
+			if status.State.Terminated.Reason != "" {
+				reason = "Init:" + status.State.Terminated.Reason
+			} else {
+				reason = fmt.Sprintf("Init:ExitCode:%d", status.State.Terminated.ExitCode)
+			}
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + status.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing {
+		hasRunning := false
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			status := pod.Status.ContainerStatuses[i]
+			switch {
+			case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+				reason = status.State.Waiting.Reason
+			case status.State.Terminated != nil && status.State.Terminated.Reason != "":
+				reason = status.State.Terminated.Reason
+			case status.State.Terminated != nil && status.State.Terminated.Signal != 0:
+				reason = fmt.Sprintf("Signal:%d", status.State.Terminated.Signal)
+			case status.State.Terminated != nil:
+				reason = fmt.Sprintf("ExitCode:%d", status.State.Terminated.ExitCode)
+			case status.Ready && status.State.Running != nil:
+				hasRunning = true
+			}
+		}
+		if reason == "Completed" && hasRunning {
+			reason = string(corev1.PodRunning)
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		if pod.Status.Reason == "NodeLost" {
+			return "Unknown"
+		}
+		return "Terminating"
+	}
+	return reason
+}
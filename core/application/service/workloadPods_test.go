@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func waitingState(reason string) corev1.ContainerState {
+	return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}
+}
+
+func terminatedState(reason string, exitCode, signal int32) corev1.ContainerState {
+	return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode, Signal: signal}}
+}
+
+var runningState = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+
+func TestPodStatus(t *testing.T) {
+	now := metav1.Now()
+	tests := []struct {
+		name       string
+		phase      corev1.PodPhase
+		reason     string
+		init       []corev1.ContainerState
+		containers []corev1.ContainerState
+		ready      bool
+		deleting   bool
+		want       string
+	}{
+		{name: "running", phase: corev1.PodRunning, containers: []corev1.ContainerState{runningState}, ready: true, want: "Running"},
+		{name: "pending without status", phase: corev1.PodPending, want: "Pending"},
+		{name: "pod reason", phase: corev1.PodFailed, reason: "Evicted", want: "Evicted"},
+		{name: "crash loop", phase: corev1.PodRunning, containers: []corev1.ContainerState{waitingState("CrashLoopBackOff")}, want: "CrashLoopBackOff"},
+		{name: "image pull", phase: corev1.PodPending, containers: []corev1.ContainerState{waitingState("ImagePullBackOff")}, want: "ImagePullBackOff"},
+		{name: "terminated reason", phase: corev1.PodFailed, containers: []corev1.ContainerState{terminatedState("OOMKilled", 137, 0)}, want: "OOMKilled"},
+		{name: "terminated signal", phase: corev1.PodFailed, containers: []corev1.ContainerState{terminatedState("", 0, 9)}, want: "Signal:9"},
+		{name: "terminated exit code", phase: corev1.PodFailed, containers: []corev1.ContainerState{terminatedState("", 2, 0)}, want: "ExitCode:2"},
+		// 第一个容器的状态优先
+		{name: "first container wins", phase: corev1.PodRunning, containers: []corev1.ContainerState{waitingState("CrashLoopBackOff"), waitingState("ImagePullBackOff")}, want: "CrashLoopBackOff"},
+		{name: "completed with running sidecar", phase: corev1.PodRunning, containers: []corev1.ContainerState{terminatedState("Completed", 0, 0), runningState}, ready: true, want: "Running"},
+		{name: "init running", phase: corev1.PodPending, init: []corev1.ContainerState{terminatedState("Completed", 0, 0), runningState}, want: "Init:1/2"},
+		{name: "init waiting", phase: corev1.PodPending, init: []corev1.ContainerState{waitingState("ImagePullBackOff")}, want: "Init:ImagePullBackOff"},
+		{name: "init pod initializing", phase: corev1.PodPending, init: []corev1.ContainerState{waitingState("PodInitializing")}, want: "Init:0/1"},
+		{name: "init failed", phase: corev1.PodPending, init: []corev1.ContainerState{terminatedState("Error", 1, 0)}, want: "Init:Error"},
+		{name: "init exit code", phase: corev1.PodPending, init: []corev1.ContainerState{terminatedState("", 3, 0)}, want: "Init:ExitCode:3"},
+		{name: "init done", phase: corev1.PodRunning, init: []corev1.ContainerState{terminatedState("Completed", 0, 0)}, containers: []corev1.ContainerState{runningState}, ready: true, want: "Running"},
+		{name: "terminating", phase: corev1.PodRunning, containers: []corev1.ContainerState{runningState}, ready: true, deleting: true, want: "Terminating"},
+		{name: "node lost", phase: corev1.PodRunning, reason: "NodeLost", deleting: true, want: "Unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Status: corev1.PodStatus{Phase: tt.phase, Reason: tt.reason}}
+			if tt.deleting {
+				pod.DeletionTimestamp = &now
+			}
+			for _, state := range tt.init {
+				pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{})
+				pod.Status.InitContainerStatuses = append(pod.Status.InitContainerStatuses, corev1.ContainerStatus{State: state})
+			}
+			for _, state := range tt.containers {
+				pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{})
+				pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{State: state, Ready: tt.ready && state.Running != nil})
+			}
+			if got := podStatus(pod); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDeploymentPods(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "prod",
+			UID:         types.UID("web-uid"),
+			Annotations: map[string]string{revisionAnnotation: "2"},
+		},
+		Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}
+	oldReplicaSet := testReplicaSet(deployment, 1, "web:v1", "")
+	oldReplicaSet.UID = "rs-1"
+	newReplicaSet := testReplicaSet(deployment, 2, "web:v2", "")
+	newReplicaSet.UID = "rs-2"
+	// 选择器相同但不属于该 Deployment 的 ReplicaSet
+	otherReplicaSet := testReplicaSet(deployment, 3, "web:v3", "")
+	otherReplicaSet.Name, otherReplicaSet.UID, otherReplicaSet.OwnerReferences = "other", "rs-other", nil
+
+	pod := func(name string, owner *appsv1.ReplicaSet) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "prod",
+			Labels:          map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))},
+		}}
+	}
+	orphan := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-orphan", Namespace: "prod", Labels: map[string]string{"app": "web"}}}
+	clientset := fake.NewSimpleClientset(oldReplicaSet, newReplicaSet, otherReplicaSet,
+		pod("web-2-b", newReplicaSet), pod("web-1-a", oldReplicaSet), pod("web-other", otherReplicaSet), orphan)
+
+	pods, err := deploymentPods(context.Background(), liveReader{clientset: clientset}, deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name, owner, revision string
+		current               bool
+	}{
+		{"web-1-a", oldReplicaSet.Name, "1", false},
+		{"web-2-b", newReplicaSet.Name, "2", true},
+	}
+	if len(pods) != len(want) {
+		t.Fatalf("expected %d pods, got %d", len(want), len(pods))
+	}
+	for i, pod := range pods {
+		if pod.Name != want[i].name || pod.owner != want[i].owner || pod.revision != want[i].revision || pod.current != want[i].current {
+			t.Fatalf("unexpected pod %d: %s owner=%s revision=%s current=%v", i, pod.Name, pod.owner, pod.revision, pod.current)
+		}
+	}
+}
@@ -1,6 +1,98 @@
 package entity
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	EnvironmentDev     = "dev"
+	EnvironmentStaging = "staging"
+	EnvironmentProd    = "prod"
+)
+
 type Cluster struct {
-	ID     uint   `json:"id"`
-	Config string `json:"config"`
+	ID          uint              `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Environment string            `json:"environment"`
+	Labels      map[string]string `json:"labels"`
+	Config      string            `json:"config,omitempty"`
+	// KeyID 加密 config 所用主密钥的 ID，为空表示历史遗留的明文数据
+	KeyID     string        `json:"-"`
+	Probe     *ClusterProbe `json:"probe,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// ClusterProbe 注册集群时连接 API Server 得到的探测结果
+type ClusterProbe struct {
+	ProbedAt      time.Time `json:"probedAt"`
+	Server        string    `json:"server"`
+	Context       string    `json:"context"`
+	Reachable     bool      `json:"reachable"`
+	Error         string    `json:"error,omitempty"`
+	ServerVersion string    `json:"serverVersion,omitempty"`
+	Platform      string    `json:"platform,omitempty"`
+	NodeCount     *int      `json:"nodeCount,omitempty"`
+	NodeError     string    `json:"nodeError,omitempty"`
+	// Namespace 权限检查所针对的命名空间
+	Namespace             string           `json:"namespace"`
+	Permissions           []PermissionRule `json:"permissions,omitempty"`
+	PermissionsIncomplete bool             `json:"permissionsIncomplete,omitempty"`
+	PermissionError       string           `json:"permissionError,omitempty"`
+}
+
+// PermissionRule 当前凭据拥有的一条权限规则，来自 SelfSubjectRulesReview
+type PermissionRule struct {
+	Verbs           []string `json:"verbs"`
+	APIGroups       []string `json:"apiGroups,omitempty"`
+	Resources       []string `json:"resources,omitempty"`
+	ResourceNames   []string `json:"resourceNames,omitempty"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+}
+
+// ClusterRef 引用一个集群，JSON 中既可以是数字 ID，也可以是集群名称
+type ClusterRef struct {
+	ID   int
+	Name string
+}
+
+// ParseClusterRef 解析字符串形式的集群引用，纯数字视为 ID，否则视为名称
+func ParseClusterRef(value string) ClusterRef {
+	if id, err := strconv.Atoi(value); err == nil {
+		return ClusterRef{ID: id}
+	}
+	return ClusterRef{Name: value}
+}
+
+func (r ClusterRef) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return strconv.Itoa(r.ID)
+}
+
+func (r *ClusterRef) UnmarshalJSON(data []byte) error {
+	var id int
+	if err := json.Unmarshal(data, &id); err == nil {
+		*r = ClusterRef{ID: id}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("cluster reference must be an ID or a name: %v", err)
+	}
+	*r = ParseClusterRef(value)
+	return nil
+}
+
+func (r ClusterRef) MarshalJSON() ([]byte, error) {
+	if r.Name != "" {
+		return json.Marshal(r.Name)
+	}
+	return json.Marshal(r.ID)
 }
@@ -18,7 +18,8 @@ func NewDB() *sql.DB {
 	dbPassword := os.Getenv("DB_PASSWORD")
 	dbName := os.Getenv("DB_NAME") */
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", "root", "root", "localhost", "3306", "simplek8s")
+	// parseTime 用于扫描时间列，clientFoundRows 让 UPDATE 在数据未变化时也返回匹配的行数
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&clientFoundRows=true", "root", "root", "localhost", "3306", "simplek8s")
 
 	for i := 0; i < 10; i++ {
 		db, err = sql.Open("mysql", dsn)
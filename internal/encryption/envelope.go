@@ -0,0 +1,117 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// dataKeySize 每条数据独立生成的 AES-256 数据密钥长度
+const dataKeySize = 32
+
+// Sealed 信封加密的结果，三部分需要一起保存
+type Sealed struct {
+	// KeyID 加密数据密钥所用主密钥的 ID
+	KeyID string
+	// DataKey 被主密钥加密后的数据密钥
+	DataKey []byte
+	// Ciphertext 被数据密钥加密后的数据
+	Ciphertext []byte
+}
+
+// Envelope 信封加密：每次加密生成随机数据密钥加密数据，再由 KeyProvider 的主密钥加密数据密钥。
+// 轮换主密钥时只需重新加密数据密钥所在的行。加解密时传入的附加数据（如行 ID）同时绑定数据和数据密钥，
+// 密文被挪到附加数据不同的位置后无法解密
+type Envelope struct {
+	provider KeyProvider
+}
+
+func NewEnvelope(provider KeyProvider) *Envelope {
+	return &Envelope{provider: provider}
+}
+
+// PrimaryKeyID 返回加密新数据时使用的主密钥 ID
+func (e *Envelope) PrimaryKeyID() string {
+	return e.provider.PrimaryKeyID()
+}
+
+// Encrypt 使用主密钥加密数据，additionalData 不加密，但解密时必须一致
+func (e *Envelope) Encrypt(plaintext, additionalData []byte) (Sealed, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return Sealed{}, fmt.Errorf("failed to generate data key: %v", err)
+	}
+
+	ciphertext, err := seal(dataKey, plaintext, additionalData)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	keyID := e.provider.PrimaryKeyID()
+	wrapped, err := e.provider.WrapKey(keyID, dataKey, additionalData)
+	if err != nil {
+		return Sealed{}, fmt.Errorf("failed to wrap data key: %v", err)
+	}
+
+	return Sealed{KeyID: keyID, DataKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Decrypt 解密 Encrypt 的结果，additionalData 必须与加密时相同
+func (e *Envelope) Decrypt(sealed Sealed, additionalData []byte) ([]byte, error) {
+	dataKey, err := e.provider.UnwrapKey(sealed.KeyID, sealed.DataKey, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %v", err)
+	}
+
+	plaintext, err := open(dataKey, sealed.Ciphertext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+// seal 使用 AES-GCM 加密，随机 nonce 放在密文前面
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open 解密 seal 的结果
+func open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, data := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, data, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %v", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %v", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,202 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func testProvider(t *testing.T, keys map[string][]byte, primary string) KeyProvider {
+	t.Helper()
+	provider, err := NewLocalKeyProvider(keys, primary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	envelope := NewEnvelope(testProvider(t, map[string][]byte{"k1": testKey(1)}, "k1"))
+	plaintexts := [][]byte{
+		[]byte("apiVersion: v1\nkind: Config\n"),
+		[]byte("集群配置"),
+		{},
+	}
+	for _, plaintext := range plaintexts {
+		sealed, err := envelope.Encrypt(plaintext, []byte("row/1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sealed.KeyID != "k1" {
+			t.Fatalf("expected key id k1, got %q", sealed.KeyID)
+		}
+		if len(plaintext) > 0 && bytes.Contains(sealed.Ciphertext, plaintext) {
+			t.Fatalf("ciphertext contains plaintext")
+		}
+		got, err := envelope.Decrypt(sealed, []byte("row/1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("expected %q, got %q", plaintext, got)
+		}
+	}
+}
+
+func TestEnvelopeUniqueDataKeys(t *testing.T) {
+	envelope := NewEnvelope(testProvider(t, map[string][]byte{"k1": testKey(1)}, "k1"))
+	first, err := envelope.Encrypt([]byte("config"), []byte("row/1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := envelope.Encrypt([]byte("config"), []byte("row/1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first.DataKey, second.DataKey) || bytes.Equal(first.Ciphertext, second.Ciphertext) {
+		t.Fatalf("expected every encryption to use a fresh data key and nonce")
+	}
+}
+
+func TestEnvelopeRotation(t *testing.T) {
+	keys := map[string][]byte{"old": testKey(1), "new": testKey(2)}
+	sealed, err := NewEnvelope(testProvider(t, keys, "old")).Encrypt([]byte("config"), []byte("row/1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 切换主密钥后旧数据仍可以用旧密钥解密
+	rotated := NewEnvelope(testProvider(t, keys, "new"))
+	got, err := rotated.Decrypt(sealed, []byte("row/1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "config" {
+		t.Fatalf("expected config, got %q", got)
+	}
+	if rotated.PrimaryKeyID() != "new" {
+		t.Fatalf("expected primary key new, got %q", rotated.PrimaryKeyID())
+	}
+}
+
+func TestEnvelopeDecryptErrors(t *testing.T) {
+	keys := map[string][]byte{"k1": testKey(1), "k2": testKey(2)}
+	sealed, err := NewEnvelope(testProvider(t, keys, "k1")).Encrypt([]byte("config"), []byte("row/1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flip := func(data []byte) []byte {
+		data = append([]byte(nil), data...)
+		data[len(data)-1] ^= 0xff
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		provider KeyProvider
+		sealed   Sealed
+		aad      string
+		want     string
+	}{
+		{"unknown key id", testProvider(t, keys, "k1"), Sealed{KeyID: "k3", DataKey: sealed.DataKey, Ciphertext: sealed.Ciphertext}, "row/1", `encryption key "k3" is not configured`},
+		{"data key moved to another key id", testProvider(t, keys, "k1"), Sealed{KeyID: "k2", DataKey: sealed.DataKey, Ciphertext: sealed.Ciphertext}, "row/1", "failed to unwrap data key"},
+		{"same key id with different key", testProvider(t, map[string][]byte{"k1": testKey(9)}, "k1"), sealed, "row/1", "failed to unwrap data key"},
+		{"tampered data key", testProvider(t, keys, "k1"), Sealed{KeyID: "k1", DataKey: flip(sealed.DataKey), Ciphertext: sealed.Ciphertext}, "row/1", "failed to unwrap data key"},
+		{"tampered ciphertext", testProvider(t, keys, "k1"), Sealed{KeyID: "k1", DataKey: sealed.DataKey, Ciphertext: flip(sealed.Ciphertext)}, "row/1", "failed to decrypt"},
+		{"truncated ciphertext", testProvider(t, keys, "k1"), Sealed{KeyID: "k1", DataKey: sealed.DataKey, Ciphertext: sealed.Ciphertext[:4]}, "row/1", "ciphertext too short"},
+		{"moved to another row", testProvider(t, keys, "k1"), sealed, "row/2", "failed to unwrap data key"},
+		{"no additional data", testProvider(t, keys, "k1"), sealed, "", "failed to unwrap data key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEnvelope(tt.provider).Decrypt(tt.sealed, []byte(tt.aad))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewLocalKeyProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    map[string][]byte
+		primary string
+		want    string
+	}{
+		{"valid", map[string][]byte{"k1": testKey(1)}, "k1", ""},
+		{"no keys", nil, "k1", "no encryption keys configured"},
+		{"empty id", map[string][]byte{"": testKey(1)}, "", "key id must not be empty"},
+		{"short key", map[string][]byte{"k1": testKey(1)[:16]}, "k1", "must be 32 bytes, got 16"},
+		{"unknown primary", map[string][]byte{"k1": testKey(1)}, "k2", `primary encryption key "k2" is not configured`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLocalKeyProvider(tt.keys, tt.primary)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewKeyProviderFromEnv(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(testKey(1))
+	k2 := base64.StdEncoding.EncodeToString(testKey(2))
+	keyFile := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(keyFile, []byte("# 主密钥\nfile-key="+k1+"\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		keys        string
+		keyFile     string
+		primaryKey  string
+		wantPrimary string
+		wantErr     string
+	}{
+		{name: "first key is primary", keys: "k1=" + k1 + ", k2=" + k2, wantPrimary: "k1"},
+		{name: "explicit primary", keys: "k1=" + k1 + ",k2=" + k2, primaryKey: "k2", wantPrimary: "k2"},
+		{name: "key file before env", keys: "k2=" + k2, keyFile: keyFile, wantPrimary: "file-key"},
+		{name: "duplicate id in env", keys: "k1=" + k1 + ",k1=" + k2, wantErr: `duplicate encryption key id "k1"`},
+		{name: "duplicate id across file and env", keys: "file-key=" + k2, keyFile: keyFile, wantErr: `duplicate encryption key id "file-key"`},
+		{name: "nothing configured", wantErr: "no encryption keys configured"},
+		{name: "missing separator", keys: "k1", wantErr: "expected keyID=base64key"},
+		{name: "invalid base64", keys: "k1=!!!", wantErr: `failed to decode encryption key "k1"`},
+		{name: "missing key file", keyFile: filepath.Join(t.TempDir(), "missing"), wantErr: "failed to read encryption key file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvKeys, tt.keys)
+			t.Setenv(EnvKeyFile, tt.keyFile)
+			t.Setenv(EnvPrimaryKey, tt.primaryKey)
+			provider, err := NewKeyProviderFromEnv()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if provider.PrimaryKeyID() != tt.wantPrimary {
+				t.Fatalf("expected primary key %q, got %q", tt.wantPrimary, provider.PrimaryKeyID())
+			}
+		})
+	}
+}
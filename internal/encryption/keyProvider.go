@@ -0,0 +1,128 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// EnvKeys 逗号分隔的 keyID=base64(32 字节密钥) 列表
+	EnvKeys = "SIMPLEK8S_ENCRYPTION_KEYS"
+	// EnvKeyFile 密钥文件路径，每行一个 keyID=base64(32 字节密钥)，# 开头为注释
+	EnvKeyFile = "SIMPLEK8S_ENCRYPTION_KEY_FILE"
+	// EnvPrimaryKey 加密新数据使用的密钥 ID，为空时使用第一个密钥
+	EnvPrimaryKey = "SIMPLEK8S_ENCRYPTION_PRIMARY_KEY"
+)
+
+// KeyProvider 管理用于加密数据密钥的主密钥，可以替换为 KMS 等外部实现
+type KeyProvider interface {
+	// PrimaryKeyID 返回加密新数据时使用的主密钥 ID
+	PrimaryKeyID() string
+	// WrapKey 使用指定主密钥加密数据密钥，additionalData 与数据密钥绑定，解密时必须一致
+	WrapKey(keyID string, dataKey, additionalData []byte) ([]byte, error)
+	// UnwrapKey 使用指定主密钥解密数据密钥
+	UnwrapKey(keyID string, wrappedKey, additionalData []byte) ([]byte, error)
+}
+
+// localKeyProvider 使用本地配置的 AES-256 主密钥
+type localKeyProvider struct {
+	keys    map[string][]byte
+	primary string
+}
+
+// NewLocalKeyProvider 使用给定的主密钥创建 KeyProvider，每个密钥必须是 32 字节
+func NewLocalKeyProvider(keys map[string][]byte, primary string) (KeyProvider, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no encryption keys configured")
+	}
+	for id, key := range keys {
+		if id == "" {
+			return nil, fmt.Errorf("encryption key id must not be empty")
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %q must be 32 bytes, got %d", id, len(key))
+		}
+	}
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary encryption key %q is not configured", primary)
+	}
+	return &localKeyProvider{keys: keys, primary: primary}, nil
+}
+
+// NewKeyProviderFromEnv 从环境变量或密钥文件加载本地主密钥
+func NewKeyProviderFromEnv() (KeyProvider, error) {
+	var entries []string
+	if path := os.Getenv(EnvKeyFile); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %v", err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, line)
+		}
+	}
+	if value := os.Getenv(EnvKeys); value != "" {
+		entries = append(entries, strings.Split(value, ",")...)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no encryption keys configured, set %s or %s", EnvKeys, EnvKeyFile)
+	}
+
+	keys := make(map[string][]byte, len(entries))
+	primary := os.Getenv(EnvPrimaryKey)
+	for _, entry := range entries {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid encryption key entry, expected keyID=base64key")
+		}
+		id = strings.TrimSpace(id)
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode encryption key %q: %v", id, err)
+		}
+		if _, ok := keys[id]; ok {
+			// 同一 ID 出现多次时后者会覆盖前者，使用旧密钥加密的数据将无法解密
+			return nil, fmt.Errorf("duplicate encryption key id %q", id)
+		}
+		keys[id] = key
+		if primary == "" {
+			primary = id
+		}
+	}
+
+	return NewLocalKeyProvider(keys, primary)
+}
+
+func (p *localKeyProvider) PrimaryKeyID() string {
+	return p.primary
+}
+
+func (p *localKeyProvider) WrapKey(keyID string, dataKey, additionalData []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q is not configured", keyID)
+	}
+	return seal(key, dataKey, wrapAdditionalData(keyID, additionalData))
+}
+
+func (p *localKeyProvider) UnwrapKey(keyID string, wrappedKey, additionalData []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q is not configured", keyID)
+	}
+	return open(key, wrappedKey, wrapAdditionalData(keyID, additionalData))
+}
+
+// wrapAdditionalData 将密钥 ID 和调用方的附加数据一起作为附加数据，防止密文被挪用到其他密钥或其他行下
+func wrapAdditionalData(keyID string, additionalData []byte) []byte {
+	return append([]byte(keyID+"\x00"), additionalData...)
+}
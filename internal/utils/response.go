@@ -22,3 +22,12 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	}
 	json.NewEncoder(w).Encode(response)
 }
+
+func RespondWithErrorJSON(w http.ResponseWriter, code int, payload interface{}) {
+	w.WriteHeader(code)
+	response := map[string]interface{}{
+		"msg":  "failure",
+		"data": payload,
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
@@ -24,6 +26,10 @@ type LogEntry struct {
 type responseRecorder struct {
 	http.ResponseWriter
 	body *bytes.Buffer
+	// streamed 流式响应不记录响应体，避免长时间的日志流占用内存
+	streamed bool
+	// hijacked 连接已被 WebSocket 接管
+	hijacked bool
 }
 
 func (rec *responseRecorder) WriteHeader(code int) {
@@ -31,30 +37,40 @@ func (rec *responseRecorder) WriteHeader(code int) {
 }
 
 func (rec *responseRecorder) Write(b []byte) (int, error) {
-	rec.body.Write(b)
+	if !rec.streamed {
+		rec.body.Write(b)
+	}
 	return rec.ResponseWriter.Write(b)
 }
 
-func LoggingMiddleware(next http.Handler, logger *zap.Logger) http.Handler {
+func (rec *responseRecorder) Flush() {
+	rec.streamed = true
+	http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+func (rec *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(rec.ResponseWriter).Hijack()
+	if err == nil {
+		rec.hijacked = true
+	}
+	return conn, buf, err
+}
+
+// Unwrap 供 http.ResponseController 访问底层的 ResponseWriter
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// LoggingMiddleware 记录请求和响应，请求地址、请求头和请求体、响应体在记录前经过 redactor 脱敏
+func LoggingMiddleware(next http.Handler, logger *zap.Logger, redactor *Redactor) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		// 获取请求头
-		requestHeader := make(map[string]string)
-		for key, values := range r.Header {
-			// 请求头的值是一个字符串切片，取第一个值
-			if len(values) > 0 {
-				requestHeader[key] = values[0]
-			}
-		}
+		// 获取请求头，屏蔽认证相关的请求头
+		requestHeader := redactor.Header(r.Header)
 		// 捕获请求体
 		requestBody, _ := io.ReadAll(r.Body)
 		// 重新赋值请求体，因为它已经被读取了
 		r.Body = io.NopCloser(bytes.NewBuffer(requestBody))
-		// 记录请求开始
-		requestMap := map[string]interface{}{
-			"header": requestHeader,
-			"body":   string(requestBody),
-		}
 		rec := &responseRecorder{
 			ResponseWriter: w,
 			body:           new(bytes.Buffer),
@@ -62,30 +78,34 @@ func LoggingMiddleware(next http.Handler, logger *zap.Logger) http.Handler {
 		next.ServeHTTP(rec, r)
 		// 记录请求完成
 		duration := time.Since(start).Milliseconds()
-		responseString := rec.body.String()
-		// 解析responseString
+		responseBody := rec.body.Bytes()
+		// 解析响应，只记录其中的 data 部分
 		var responseMap map[string]interface{}
-		if err := json.Unmarshal([]byte(responseString), &responseMap); err == nil {
+		if err := json.Unmarshal(responseBody, &responseMap); err == nil {
 			if body, ok := responseMap["body"].(map[string]interface{}); ok {
-				modifiedResponseBody, _ := json.Marshal(body)
-				responseString = string(modifiedResponseBody)
+				responseBody, _ = json.Marshal(body)
 			}
 			var bodyMap map[string]interface{}
-			if err := json.Unmarshal([]byte(responseString), &bodyMap); err == nil {
+			if err := json.Unmarshal(responseBody, &bodyMap); err == nil {
 				if data, ok := bodyMap["data"].(map[string]interface{}); ok {
-					modifiedResponseBody, _ := json.Marshal(data)
-					responseString = string(modifiedResponseBody)
+					responseBody, _ = json.Marshal(data)
 				}
 			}
 		}
 		logEntry := LogEntry{
 			Level:    "INFO",
-			Url:      r.URL.String(),
+			Url:      redactor.URL(r.URL),
 			Method:   r.Method,
 			Header:   requestHeader,
 			Duration: fmt.Sprintf("%d ms", duration),
-			Request:  requestMap["body"].(string),
-			Response: responseString,
+			Request:  redactor.RequestBody(r.Method, r.URL.Path, requestBody),
+			Response: redactor.ResponseBody(r.Method, r.URL.Path, responseBody),
+		}
+		switch {
+		case rec.hijacked:
+			logEntry.Response = "[hijacked connection]"
+		case rec.streamed:
+			logEntry.Response = "[streamed response]"
 		}
 
 		// 记录日志
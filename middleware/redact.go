@@ -0,0 +1,417 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// redacted 替换敏感内容的占位符
+const redacted = "[REDACTED]"
+
+// BodyAction 记录请求体或响应体的方式
+type BodyAction int
+
+const (
+	// BodyKeep 记录完整内容，仍会进行敏感字段检测和长度限制
+	BodyKeep BodyAction = iota
+	// BodyDrop 不记录内容，只记录长度
+	BodyDrop
+	// BodyTruncate 只记录前 MaxSize 个字节
+	BodyTruncate
+	// BodyMask 将 MaskPaths 指定的 JSON 字段替换为占位符
+	BodyMask
+)
+
+// BodyPolicy 单个路由请求体或响应体的记录策略
+type BodyPolicy struct {
+	Action BodyAction
+	// MaxSize BodyTruncate 时保留的字节数，为 0 时使用全局的 MaxBodySize
+	MaxSize int
+	// MaskPaths BodyMask 时需要屏蔽的字段路径，用 . 分隔，* 匹配任意字段或数组下标，如 items.*.data
+	MaskPaths []string
+}
+
+// RoutePolicy 单个路由的日志策略
+type RoutePolicy struct {
+	Request  BodyPolicy
+	Response BodyPolicy
+}
+
+// RedactConfig 日志脱敏配置
+type RedactConfig struct {
+	// MaxBodySize 记录的请求体、响应体的最大字节数，超出部分被截断，为 0 时不限制
+	MaxBodySize int
+	// DenyHeaders 值需要屏蔽的请求头，大小写不敏感
+	DenyHeaders []string
+	// DenyQueryParams 值需要屏蔽的查询参数，如 exec 的 command，大小写不敏感
+	DenyQueryParams []string
+	// SensitiveKeys 无论出现在 JSON 的哪一层都需要屏蔽的字段名，大小写不敏感
+	SensitiveKeys []string
+	// Routes 按路径配置的策略，{name} 匹配任意一段路径，以 / 结尾的路径按前缀匹配，未配置的路由使用 BodyKeep。
+	// 路径前可以加请求方法，如 PATCH /clusters/{cluster}/{resource}/{name}，只对该方法生效。
+	// 多个配置匹配同一请求时使用最具体的一个，见 routeEntry.moreSpecific
+	Routes map[string]RoutePolicy
+}
+
+// DefaultRedactConfig 默认的脱敏配置，屏蔽常见的认证请求头和 kubeconfig 凭据字段
+func DefaultRedactConfig() RedactConfig {
+	return RedactConfig{
+		MaxBodySize:     4096,
+		DenyHeaders:     []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token"},
+		DenyQueryParams: []string{"command", "access_token", "api_key"},
+		SensitiveKeys: []string{
+			"kubeconfig", "password", "token", "secret",
+			"client-key-data", "client-certificate-data", "certificate-authority-data",
+		},
+		Routes: map[string]RoutePolicy{},
+	}
+}
+
+// Redactor 在记录日志前屏蔽请求头和请求体、响应体中的敏感内容
+type Redactor struct {
+	config        RedactConfig
+	denyHeaders   map[string]bool
+	denyQuery     map[string]bool
+	sensitiveKeys map[string]bool
+	routes        []routeEntry
+}
+
+// routeMatch 路由的匹配方式，数值越大优先级越高
+type routeMatch int
+
+const (
+	matchPrefix routeMatch = iota
+	matchTemplate
+	matchExact
+)
+
+// routeEntry 解析后的 Routes 配置项
+type routeEntry struct {
+	// method 为空时匹配所有请求方法
+	method  string
+	pattern string
+	match   routeMatch
+	// specificity 模板中固定路径段的数量或前缀的长度，越大越具体
+	specificity int
+	policy      RoutePolicy
+}
+
+func NewRedactor(config RedactConfig) *Redactor {
+	r := &Redactor{
+		config:        config,
+		denyHeaders:   make(map[string]bool, len(config.DenyHeaders)),
+		denyQuery:     make(map[string]bool, len(config.DenyQueryParams)),
+		sensitiveKeys: make(map[string]bool, len(config.SensitiveKeys)),
+	}
+	for _, header := range config.DenyHeaders {
+		r.denyHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, key := range config.DenyQueryParams {
+		r.denyQuery[strings.ToLower(key)] = true
+	}
+	for _, key := range config.SensitiveKeys {
+		r.sensitiveKeys[strings.ToLower(key)] = true
+	}
+	for key, policy := range config.Routes {
+		r.routes = append(r.routes, newRouteEntry(key, policy))
+	}
+	return r
+}
+
+// Header 返回用于记录的请求头，每个请求头取第一个值，拒绝列表中的值被屏蔽
+func (r *Redactor) Header(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for key, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+		if r.denyHeaders[http.CanonicalHeaderKey(key)] {
+			result[key] = redacted
+			continue
+		}
+		result[key] = values[0]
+	}
+	return result
+}
+
+// URL 返回用于记录的请求地址，拒绝列表中的查询参数、敏感字段名对应的参数以及疑似凭据的参数值被屏蔽
+func (r *Redactor) URL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return u.Path + "?" + redacted
+	}
+	for key, values := range query {
+		lower := strings.ToLower(key)
+		for i, value := range values {
+			if r.denyQuery[lower] || r.sensitiveKeys[lower] || looksSensitive(value) {
+				values[i] = redacted
+			}
+		}
+	}
+	return u.Path + "?" + query.Encode()
+}
+
+// RequestBody 按路由策略返回用于记录的请求体
+func (r *Redactor) RequestBody(method, path string, body []byte) string {
+	return r.body(r.route(method, path).Request, body, isSecretPath(path))
+}
+
+// ResponseBody 按路由策略返回用于记录的响应体
+func (r *Redactor) ResponseBody(method, path string, body []byte) string {
+	return r.body(r.route(method, path).Response, body, isSecretPath(path))
+}
+
+// isSecretPath 判断路径是否指向 Secret，这类请求的补丁等内容不带 kind，需要按 Secret 处理
+func isSecretPath(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "secrets" {
+			return true
+		}
+	}
+	return false
+}
+
+// newRouteEntry 解析形如 /clusters/{cluster}/config 或 PATCH /clusters/{cluster}/{resource}/{name} 的配置项
+func newRouteEntry(key string, policy RoutePolicy) routeEntry {
+	entry := routeEntry{pattern: key, policy: policy}
+	if method, pattern, ok := strings.Cut(key, " "); ok {
+		entry.method, entry.pattern = strings.ToUpper(method), strings.TrimSpace(pattern)
+	}
+	switch {
+	case strings.Contains(entry.pattern, "{"):
+		entry.match = matchTemplate
+		for _, segment := range strings.Split(entry.pattern, "/") {
+			if segment != "" && !strings.HasPrefix(segment, "{") {
+				entry.specificity++
+			}
+		}
+	case strings.HasSuffix(entry.pattern, "/"):
+		entry.match = matchPrefix
+		entry.specificity = len(entry.pattern)
+	default:
+		entry.match = matchExact
+	}
+	return entry
+}
+
+func (e *routeEntry) matches(method, path string) bool {
+	if e.method != "" && e.method != method {
+		return false
+	}
+	switch e.match {
+	case matchExact:
+		return e.pattern == path
+	case matchTemplate:
+		return matchesTemplate(e.pattern, path)
+	default:
+		return strings.HasPrefix(path, e.pattern)
+	}
+}
+
+// moreSpecific 精确匹配优先于模板匹配，模板匹配优先于前缀匹配；同类中固定部分多的模板、长的前缀优先，
+// 其次是指定了请求方法的配置，最后按字符串排序，保证多个配置匹配同一请求时结果固定
+func (e *routeEntry) moreSpecific(other *routeEntry) bool {
+	if e.match != other.match {
+		return e.match > other.match
+	}
+	if e.specificity != other.specificity {
+		return e.specificity > other.specificity
+	}
+	if (e.method != "") != (other.method != "") {
+		return e.method != ""
+	}
+	return e.pattern < other.pattern
+}
+
+// route 查找请求对应的策略，未配置时使用 BodyKeep
+func (r *Redactor) route(method, path string) RoutePolicy {
+	var best *routeEntry
+	for i := range r.routes {
+		entry := &r.routes[i]
+		if entry.matches(method, path) && (best == nil || entry.moreSpecific(best)) {
+			best = entry
+		}
+	}
+	if best == nil {
+		return RoutePolicy{}
+	}
+	return best.policy
+}
+
+// matchesTemplate 判断路径是否匹配形如 /clusters/{cluster}/config 的模板
+func matchesTemplate(pattern, path string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(patternSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Redactor) body(policy BodyPolicy, body []byte, secret bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if policy.Action == BodyDrop {
+		return fmt.Sprintf("[dropped %d bytes]", len(body))
+	}
+
+	var masked string
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil {
+		if policy.Action == BodyMask {
+			for _, path := range policy.MaskPaths {
+				value = maskPath(value, strings.Split(path, "."))
+			}
+		}
+		if secret {
+			value = maskSecret(value)
+		}
+		value = r.maskSensitive(value)
+		data, _ := json.Marshal(value)
+		masked = string(data)
+	} else if secret || looksSensitive(string(body)) {
+		// 非 JSON 内容无法按字段屏蔽，包含凭据或属于 Secret 时整体屏蔽
+		masked = redacted
+	} else {
+		masked = string(body)
+	}
+
+	maxSize := r.config.MaxBodySize
+	if policy.Action == BodyTruncate && policy.MaxSize > 0 {
+		maxSize = policy.MaxSize
+	}
+	return truncate(masked, maxSize)
+}
+
+// maskSensitive 屏蔽敏感字段名对应的值、Secret 的 data 和 stringData，以及内嵌的 kubeconfig、Secret 清单
+func (r *Redactor) maskSensitive(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		isSecret := v["kind"] == "Secret"
+		for key, child := range v {
+			if r.sensitiveKeys[strings.ToLower(key)] || (isSecret && (key == "data" || key == "stringData")) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = r.maskSensitive(child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.maskSensitive(child)
+		}
+		return v
+	case string:
+		if looksSensitive(v) {
+			return redacted
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// maskSecret 按 Secret 处理不带 kind 的内容：屏蔽对象或 merge 补丁的 data 和 stringData，
+// 以及 JSON 补丁中除 metadata 以外的操作的 value
+func maskSecret(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range []string{"data", "stringData"} {
+			if _, ok := v[key]; ok {
+				v[key] = redacted
+			}
+		}
+		return v
+	case []interface{}:
+		for _, child := range v {
+			op, ok := child.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if path, _ := op["path"].(string); strings.HasPrefix(path, "/metadata/") {
+				continue
+			}
+			if _, ok := op["value"]; ok {
+				op["value"] = redacted
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// maskPath 屏蔽路径指向的字段，路径不存在时保持不变
+func maskPath(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return redacted
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if path[0] == "*" || path[0] == key {
+				v[key] = maskPath(child, path[1:])
+			}
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			if path[0] == "*" || path[0] == strconv.Itoa(i) {
+				v[i] = maskPath(child, path[1:])
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+var (
+	// kubeconfigPattern kubeconfig 中的凭据字段或 kind: Config
+	kubeconfigPattern = regexp.MustCompile(`(?m)(client-key-data|client-certificate-data|certificate-authority-data)\s*:|^kind:\s*Config\s*$`)
+	// secretManifestPattern YAML 清单中的 kind: Secret
+	secretManifestPattern = regexp.MustCompile(`(?m)^\s*kind:\s*["']?Secret["']?\s*$`)
+	// privateKeyPattern PEM 格式的私钥
+	privateKeyPattern = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+)
+
+// looksSensitive 判断文本是否为 kubeconfig、Secret 清单或私钥
+func looksSensitive(text string) bool {
+	return kubeconfigPattern.MatchString(text) || secretManifestPattern.MatchString(text) || privateKeyPattern.MatchString(text)
+}
+
+// truncate 保留前 maxSize 个字节，截断位置回退到字符边界，避免切开多字节的 UTF-8 字符
+func truncate(text string, maxSize int) string {
+	if maxSize <= 0 || len(text) <= maxSize {
+		return text
+	}
+	cut := maxSize
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", text[:cut], len(text)-cut)
+}
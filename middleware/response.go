@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"bufio"
 	"encoding/json"
+	"net"
 	"net/http"
 )
 
@@ -9,6 +11,10 @@ type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
 	body       []byte
+	// streaming 处理函数调用 Flush 后不再缓冲和包装响应，直接写入客户端
+	streaming bool
+	// hijacked 连接被接管（WebSocket）后不能再写入响应
+	hijacked bool
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
@@ -17,14 +23,46 @@ func (rw *responseWriter) WriteHeader(code int) {
 }
 
 func (rw *responseWriter) Write(data []byte) (int, error) {
+	if rw.streaming {
+		return rw.ResponseWriter.Write(data)
+	}
 	rw.body = append(rw.body, data...)
 	return len(data), nil
 }
 
+// Flush 流式响应（日志、SSE）通过 Flush 绕过 JSON 包装，已缓冲的内容原样写出
+func (rw *responseWriter) Flush() {
+	if !rw.streaming {
+		rw.streaming = true
+		if len(rw.body) > 0 {
+			rw.ResponseWriter.Write(rw.body)
+			rw.body = nil
+		}
+	}
+	http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Hijack WebSocket 升级时接管底层连接
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(rw.ResponseWriter).Hijack()
+	if err == nil {
+		rw.hijacked = true
+	}
+	return conn, buf, err
+}
+
+// Unwrap 供 http.ResponseController 访问底层的 ResponseWriter
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func JSONResponseMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 		next.ServeHTTP(rw, r)
+		if rw.streaming || rw.hijacked {
+			return
+		}
 
 		// 包装成固定格式的JSON响应
 		response := map[string]interface{}{
@@ -2,32 +2,180 @@ package server
 
 import (
 	"go_code/simplek8s/core/application/handler"
+	"go_code/simplek8s/internal/utils"
 	"go_code/simplek8s/middleware"
 	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
 )
 
-func NewRouter(clusterHandler *handler.ClusterHandler) http.Handler {
-	mux := http.NewServeMux()
-	RegisterRoutes(mux, clusterHandler)
+func NewRouter(clusterHandler *handler.ClusterHandler, restHandler *handler.RESTHandler) http.Handler {
+	router := mux.NewRouter()
+	router.NotFoundHandler = http.HandlerFunc(notFound)
+	router.MethodNotAllowedHandler = methodNotAllowed(router)
+	RegisterRoutes(router, restHandler)
+	RegisterLegacyRoutes(router, clusterHandler)
 
 	// 使用中间件的顺序：先恢复 panic，再记录日志，最后处理 JSON 响应
-	recoverMiddleware := middleware.RecoverMiddleware(mux, Logger)
-	loggingMiddleware := middleware.LoggingMiddleware(recoverMiddleware, Logger)
+	recoverMiddleware := middleware.RecoverMiddleware(router, Logger)
+	loggingMiddleware := middleware.LoggingMiddleware(recoverMiddleware, Logger, middleware.NewRedactor(logRedactConfig()))
 	finalHandler := middleware.JSONResponseMiddleware(loggingMiddleware)
 
 	return finalHandler
 }
 
-func RegisterRoutes(mux *http.ServeMux, clusterHandler *handler.ClusterHandler) {
-	// 添加路由，并将请求通过中间件处理
-	mux.Handle("/cluster/add", http.HandlerFunc(clusterHandler.AddCluster))
-	mux.Handle("/deployment/create", http.HandlerFunc(clusterHandler.CreateDeployment))
-	mux.Handle("/deployment/update", http.HandlerFunc(clusterHandler.UpdateDeployment))
-	mux.Handle("/deployment/get", http.HandlerFunc(clusterHandler.GetDeployment))
-	mux.Handle("/deployment/delete", http.HandlerFunc(clusterHandler.DeleteDeployment))
-	mux.Handle("/statefulset/create", http.HandlerFunc(clusterHandler.CreateStatefulSet))
-	mux.Handle("/statefulset/update", http.HandlerFunc(clusterHandler.UpdateStatefulSet))
-	mux.Handle("/statefulset/get", http.HandlerFunc(clusterHandler.GetStatefulSet))
-	mux.Handle("/statefulset/delete", http.HandlerFunc(clusterHandler.DeleteStatefulSet))
+// logRedactConfig 各路由的日志脱敏策略，kubeconfig 和 Secret 不应出现在日志中
+func logRedactConfig() middleware.RedactConfig {
+	config := middleware.DefaultRedactConfig()
+	kubeconfig := middleware.BodyPolicy{Action: middleware.BodyMask, MaskPaths: []string{"config"}}
+	config.Routes["/clusters"] = middleware.RoutePolicy{Request: kubeconfig}
+	config.Routes["/cluster/add"] = middleware.RoutePolicy{Request: kubeconfig}
+	config.Routes["/cluster/config/update"] = middleware.RoutePolicy{Request: kubeconfig}
+	// 请求体就是 kubeconfig，不记录
+	config.Routes["/clusters/{cluster}/config"] = middleware.RoutePolicy{Request: middleware.BodyPolicy{Action: middleware.BodyDrop}}
+	// 清单可能很大，只记录开头部分
+	manifest := middleware.BodyPolicy{Action: middleware.BodyTruncate, MaxSize: 1024}
+	config.Routes["/clusters/{cluster}/manifests"] = middleware.RoutePolicy{Request: manifest}
+	config.Routes["/resource/apply"] = middleware.RoutePolicy{Request: manifest}
+	config.Routes["/resource/delete"] = middleware.RoutePolicy{Request: manifest}
+	// 探测结果包含完整的权限列表，不记录响应
+	probe := middleware.RoutePolicy{Response: middleware.BodyPolicy{Action: middleware.BodyDrop}}
+	config.Routes["/clusters/{cluster}/probe"] = probe
+	config.Routes["/cluster/probe"] = probe
+	// 补丁不带 kind，无法识别其中的 Secret 数据，不记录
+	patch := middleware.RoutePolicy{Request: middleware.BodyPolicy{Action: middleware.BodyDrop}}
+	config.Routes[http.MethodPatch+" /clusters/{cluster}/namespaces/{namespace}/{resource}/{name}"] = patch
+	config.Routes[http.MethodPatch+" /clusters/{cluster}/{resource}/{name}"] = patch
+	return config
+}
+
+// RegisterRoutes 注册面向资源的路由，{cluster} 可以是集群 ID 或名称
+func RegisterRoutes(router *mux.Router, restHandler *handler.RESTHandler) {
+	router.HandleFunc("/clients/stats", restHandler.GetClientPoolStats).Methods(http.MethodGet)
+	router.HandleFunc("/cache/stats", restHandler.GetInformerCacheStats).Methods(http.MethodGet)
+
+	router.HandleFunc("/clusters", restHandler.ListClusters).Methods(http.MethodGet)
+	router.HandleFunc("/clusters", restHandler.AddCluster).Methods(http.MethodPost)
+	router.HandleFunc("/clusters/{cluster}", restHandler.GetCluster).Methods(http.MethodGet)
+	router.HandleFunc("/clusters/{cluster}", restHandler.UpdateCluster).Methods(http.MethodPatch)
+	router.HandleFunc("/clusters/{cluster}", restHandler.DeleteCluster).Methods(http.MethodDelete)
+	router.HandleFunc("/clusters/{cluster}/config", restHandler.UpdateClusterConfig).Methods(http.MethodPut)
+	router.HandleFunc("/clusters/{cluster}/probe", restHandler.ProbeCluster).Methods(http.MethodPost)
+	router.HandleFunc("/clusters/{cluster}/cache", restHandler.GetClusterCacheStatus).Methods(http.MethodGet)
+
+	router.HandleFunc("/clusters/{cluster}/manifests", restHandler.ApplyManifest).Methods(http.MethodPut)
+	router.HandleFunc("/clusters/{cluster}/manifests", restHandler.DeleteManifest).Methods(http.MethodDelete)
+
+	router.HandleFunc("/clusters/{cluster}/{workload:"+handler.WorkloadPattern+"}", restHandler.ListWorkloads).Methods(http.MethodGet)
+	workloads := "/clusters/{cluster}/namespaces/{namespace}/{workload:" + handler.WorkloadPattern + "}"
+	router.HandleFunc(workloads, restHandler.ListWorkloads).Methods(http.MethodGet)
+	router.HandleFunc(workloads, restHandler.CreateWorkload).Methods(http.MethodPost)
+	router.HandleFunc(workloads+"/{name}", restHandler.GetWorkload).Methods(http.MethodGet)
+	router.HandleFunc(workloads+"/{name}", restHandler.UpdateWorkload).Methods(http.MethodPut)
+	router.HandleFunc(workloads+"/{name}", restHandler.DeleteWorkload).Methods(http.MethodDelete)
+	router.HandleFunc(workloads+"/{name}/pods", restHandler.ListWorkloadPods).Methods(http.MethodGet)
+	router.HandleFunc(workloads+"/{name}/logs", restHandler.GetWorkloadLogs).Methods(http.MethodGet)
+	router.HandleFunc(workloads+"/{name}/events", restHandler.ListWorkloadEvents).Methods(http.MethodGet)
+	router.HandleFunc(workloads+"/{name}/rollout/status", restHandler.GetRolloutStatus).Methods(http.MethodGet)
+	router.HandleFunc(workloads+"/{name}/rollout/history", restHandler.GetRolloutHistory).Methods(http.MethodGet)
+	router.HandleFunc(workloads+"/{name}/rollout/history/diff", restHandler.DiffRevisions).Methods(http.MethodGet)
+	router.HandleFunc(workloads+"/{name}/rollout/undo", restHandler.UndoRollout).Methods(http.MethodPost)
+	restartable := "/clusters/{cluster}/namespaces/{namespace}/{workload:" + handler.RestartablePattern + "}"
+	router.HandleFunc(restartable+"/{name}/rollout/restart", restHandler.RestartRollout).Methods(http.MethodPost)
+	router.HandleFunc(restartable+"/{name}/images", restHandler.SetImage).Methods(http.MethodPut)
+	deployments := "/clusters/{cluster}/namespaces/{namespace}/deployments"
+	router.HandleFunc(deployments+"/{name}/rollout/pause", restHandler.PauseRollout).Methods(http.MethodPost)
+	router.HandleFunc(deployments+"/{name}/rollout/resume", restHandler.ResumeRollout).Methods(http.MethodPost)
+
+	// 日志以流的形式返回，不经过 JSON 包装
+	router.HandleFunc("/clusters/{cluster}/namespaces/{namespace}/pods/{pod}/logs", restHandler.GetPodLogs).Methods(http.MethodGet)
+	// exec 升级为 WebSocket，连接被接管后中间件不再写入响应
+	router.HandleFunc("/clusters/{cluster}/namespaces/{namespace}/pods/{pod}/exec", restHandler.ExecPod).Methods(http.MethodGet)
+	// 以 Server-Sent Events 推送资源变化，不带命名空间时监听所有命名空间或集群级资源
+	router.HandleFunc("/clusters/{cluster}/watch/{resource}", restHandler.WatchResource).Methods(http.MethodGet)
+	router.HandleFunc("/clusters/{cluster}/namespaces/{namespace}/watch/{resource}", restHandler.WatchResource).Methods(http.MethodGet)
+
+	// {resource} 为资源的复数名称，CRD 使用 resource.group 形式，如 rollouts.argoproj.io
+	resources := "/clusters/{cluster}/namespaces/{namespace}/{resource}/{name}"
+	router.HandleFunc(resources+"/scale", restHandler.GetScale).Methods(http.MethodGet)
+	router.HandleFunc(resources+"/scale", restHandler.Scale).Methods(http.MethodPut)
+	router.HandleFunc(resources, restHandler.PatchResource).Methods(http.MethodPatch)
+	// 集群级资源，如 nodes、namespaces、clusterroles
+	router.HandleFunc("/clusters/{cluster}/{resource}/{name}", restHandler.PatchResource).Methods(http.MethodPatch)
 	Logger.Info("Routes registered")
 }
+
+// RegisterLegacyRoutes 注册旧的动词式路由，参数通过 JSON 请求体传递，不限制请求方法。
+// 这些路由已废弃，响应中带有 Deprecation 头和指向新路由的 Link 头
+func RegisterLegacyRoutes(router *mux.Router, clusterHandler *handler.ClusterHandler) {
+	legacy := func(path, successor string, handlerFunc http.HandlerFunc) {
+		router.Handle(path, deprecated(successor, handlerFunc))
+	}
+	workload := "/clusters/{cluster}/namespaces/{namespace}/deployments"
+	statefulSet := "/clusters/{cluster}/namespaces/{namespace}/statefulsets"
+
+	legacy("/cluster/add", "/clusters", clusterHandler.AddCluster)
+	legacy("/cluster/list", "/clusters", clusterHandler.ListClusters)
+	legacy("/cluster/get", "/clusters/{cluster}", clusterHandler.GetCluster)
+	legacy("/cluster/update", "/clusters/{cluster}", clusterHandler.UpdateCluster)
+	legacy("/cluster/config/update", "/clusters/{cluster}/config", clusterHandler.UpdateClusterConfig)
+	legacy("/cluster/delete", "/clusters/{cluster}", clusterHandler.DeleteCluster)
+	legacy("/cluster/probe", "/clusters/{cluster}/probe", clusterHandler.ProbeCluster)
+	legacy("/cluster/pool/stats", "/clients/stats", clusterHandler.GetClientPoolStats)
+	legacy("/deployment/create", workload, clusterHandler.CreateDeployment)
+	legacy("/deployment/update", workload+"/{name}", clusterHandler.UpdateDeployment)
+	legacy("/deployment/get", workload+"/{name}", clusterHandler.GetDeployment)
+	legacy("/deployment/delete", workload+"/{name}", clusterHandler.DeleteDeployment)
+	legacy("/statefulset/create", statefulSet, clusterHandler.CreateStatefulSet)
+	legacy("/statefulset/update", statefulSet+"/{name}", clusterHandler.UpdateStatefulSet)
+	legacy("/statefulset/get", statefulSet+"/{name}", clusterHandler.GetStatefulSet)
+	legacy("/statefulset/delete", statefulSet+"/{name}", clusterHandler.DeleteStatefulSet)
+	legacy("/resource/apply", "/clusters/{cluster}/manifests", clusterHandler.ApplyResource)
+	legacy("/resource/delete", "/clusters/{cluster}/manifests", clusterHandler.DeleteResource)
+	Logger.Info("Legacy routes registered")
+}
+
+// deprecated 为废弃的路由添加 Deprecation 和 Link 响应头
+func deprecated(successor string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithError(w, http.StatusNotFound, "No route matches "+r.URL.Path)
+}
+
+// methodNotAllowed 路径存在但方法不匹配时返回 405，并在 Allow 头中列出该路径支持的方法
+func methodNotAllowed(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := map[string]bool{}
+		router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			methods, err := route.GetMethods()
+			if err != nil {
+				return nil
+			}
+			for _, method := range methods {
+				probe := r.Clone(r.Context())
+				probe.Method = method
+				var match mux.RouteMatch
+				if route.Match(probe, &match) && match.MatchErr == nil {
+					allowed[method] = true
+				}
+			}
+			return nil
+		})
+
+		methods := make([]string, 0, len(allowed))
+		for method := range allowed {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "Method "+r.Method+" is not allowed on "+r.URL.Path)
+	})
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"go_code/simplek8s/middleware"
+)
+
+func TestLogRedactConfigPatch(t *testing.T) {
+	redactor := middleware.NewRedactor(logRedactConfig())
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"namespaced merge patch", "/clusters/1/namespaces/prod/configmaps/app", `{"data":{"password-hint":"hunter2"}}`},
+		{"secret json patch", "/clusters/prod/namespaces/prod/secrets/db", `[{"op":"replace","path":"/data/api-key","value":"hunter2"}]`},
+		{"cluster-scoped patch", "/clusters/1/nodes/node-1", `{"metadata":{"annotations":{"note":"hunter2"}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactor.RequestBody(http.MethodPatch, tt.path, []byte(tt.body))
+			if strings.Contains(got, "hunter2") || !strings.HasPrefix(got, "[dropped ") {
+				t.Fatalf("expected patch body to be dropped, got %s", got)
+			}
+		})
+	}
+}
+
+func TestLogRedactConfigPatchOnly(t *testing.T) {
+	redactor := middleware.NewRedactor(logRedactConfig())
+	manifest := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n"
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPut, "/clusters/1/namespaces/prod/deployments/web"},
+		{http.MethodPost, "/clusters/1/namespaces/prod/deployments"},
+	}
+	for _, tt := range tests {
+		if got := redactor.RequestBody(tt.method, tt.path, []byte(manifest)); got != manifest {
+			t.Fatalf("%s %s: expected manifest to be logged, got %s", tt.method, tt.path, got)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+-- 为已有的 clusters 表增加名称、描述、环境、标签和时间戳
+
+ALTER TABLE clusters
+    ADD COLUMN name VARCHAR(63) NULL AFTER id,
+    ADD COLUMN description VARCHAR(255) NOT NULL DEFAULT '' AFTER name,
+    ADD COLUMN environment VARCHAR(16) NOT NULL DEFAULT 'dev' AFTER description,
+    ADD COLUMN labels TEXT AFTER environment,
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
+
+-- 已有集群使用 cluster-<id> 作为名称，之后可通过接口重命名
+UPDATE clusters SET name = CONCAT('cluster-', id) WHERE name IS NULL;
+
+ALTER TABLE clusters
+    MODIFY COLUMN name VARCHAR(63) NOT NULL,
+    ADD UNIQUE KEY uk_clusters_name (name);
@@ -0,0 +1,3 @@
+-- 保存注册集群时的探测结果（版本、节点数、权限）
+
+ALTER TABLE clusters ADD COLUMN probe TEXT AFTER config;
@@ -0,0 +1,6 @@
+-- 加密保存 kubeconfig：记录每行使用的主密钥 ID 和被加密的数据密钥
+-- 执行后使用 cmd/reencrypt 将已有的明文数据加密
+
+ALTER TABLE clusters
+    ADD COLUMN key_id VARCHAR(64) AFTER config,
+    ADD COLUMN data_key TEXT AFTER key_id;
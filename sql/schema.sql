@@ -3,5 +3,16 @@
 -- 创建 cluster 表
 CREATE TABLE clusters (
     id INT AUTO_INCREMENT PRIMARY KEY,
-    config TEXT NOT NULL
-);
\ No newline at end of file
+    name VARCHAR(63) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    environment VARCHAR(16) NOT NULL DEFAULT 'dev',
+    labels TEXT,
+    -- config 为信封加密后的 kubeconfig（base64），密文与行 ID 绑定，key_id 为空时是历史遗留的明文
+    config TEXT NOT NULL,
+    key_id VARCHAR(64),
+    data_key TEXT,
+    probe TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_clusters_name (name)
+);
@@ -6,8 +6,10 @@ package wire
 import (
 	"go_code/simplek8s/core/application/dao"
 	"go_code/simplek8s/core/application/handler"
+	"go_code/simplek8s/core/application/repository"
 	"go_code/simplek8s/core/application/service"
 	"go_code/simplek8s/internal/database"
+	"go_code/simplek8s/internal/encryption"
 	"go_code/simplek8s/server"
 	"net/http"
 
@@ -17,10 +19,26 @@ import (
 func InitializeRouter() (http.Handler, error) {
 	wire.Build(
 		database.NewDB,
+		encryption.NewKeyProviderFromEnv,
+		encryption.NewEnvelope,
 		dao.NewClusterDao,
+		service.NewClientManager,
+		service.NewInformerCacheFromEnv,
 		service.NewClusterService,
 		handler.NewClusterHandler,
+		handler.NewRESTHandler,
 		server.NewRouter,
 	)
 	return nil, nil
 }
+
+// InitializeClusterRepo 供命令行工具直接读写集群表
+func InitializeClusterRepo() (repository.ClusterRepo, error) {
+	wire.Build(
+		database.NewDB,
+		encryption.NewKeyProviderFromEnv,
+		encryption.NewEnvelope,
+		dao.NewClusterDao,
+	)
+	return nil, nil
+}
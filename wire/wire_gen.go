@@ -9,8 +9,10 @@ package wire
 import (
 	"go_code/simplek8s/core/application/dao"
 	"go_code/simplek8s/core/application/handler"
+	"go_code/simplek8s/core/application/repository"
 	"go_code/simplek8s/core/application/service"
 	"go_code/simplek8s/internal/database"
+	"go_code/simplek8s/internal/encryption"
 	"go_code/simplek8s/server"
 	"net/http"
 )
@@ -19,9 +21,31 @@ import (
 
 func InitializeRouter() (http.Handler, error) {
 	db := database.NewDB()
-	clusterRepo := dao.NewClusterDao(db)
-	clusterService := service.NewClusterService(clusterRepo)
+	keyProvider, err := encryption.NewKeyProviderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	envelope := encryption.NewEnvelope(keyProvider)
+	clusterRepo := dao.NewClusterDao(db, envelope)
+	clientManager := service.NewClientManager(clusterRepo)
+	informerCache, err := service.NewInformerCacheFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	clusterService := service.NewClusterService(clusterRepo, clientManager, informerCache)
 	clusterHandler := handler.NewClusterHandler(clusterService)
-	httpHandler := server.NewRouter(clusterHandler)
+	restHandler := handler.NewRESTHandler(clusterHandler)
+	httpHandler := server.NewRouter(clusterHandler, restHandler)
 	return httpHandler, nil
 }
+
+func InitializeClusterRepo() (repository.ClusterRepo, error) {
+	db := database.NewDB()
+	keyProvider, err := encryption.NewKeyProviderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	envelope := encryption.NewEnvelope(keyProvider)
+	clusterRepo := dao.NewClusterDao(db, envelope)
+	return clusterRepo, nil
+}